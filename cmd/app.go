@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/goccy/go-yaml"
 	"github.com/pepabo/onecli/onelogin"
 	"github.com/pepabo/onecli/utils"
 	"github.com/spf13/cobra"
@@ -20,6 +21,7 @@ var (
 	appQueryName string
 	appOutput    string
 	appDetail    bool
+	appRuleFile  string
 )
 
 var appListCmd = &cobra.Command{
@@ -85,6 +87,224 @@ var appListUsersCmd = &cobra.Command{
 	},
 }
 
+var appRolesCmd = &cobra.Command{
+	Use:          "roles <app-id>",
+	Short:        "List roles for a specific app",
+	Long:         `List all roles that grant access to a specific app in your OneLogin organization`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		roles, err := client.GetAppRoles(appID)
+		if err != nil {
+			return fmt.Errorf("error getting app roles: %v", err)
+		}
+
+		if err := utils.PrintOutput(roles, utils.OutputFormat(appOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var appRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "App rule management commands",
+	Long:  `Commands for managing the rules (conditions and actions) of a OneLogin app`,
+}
+
+var appRulesListCmd = &cobra.Command{
+	Use:          "list <app-id>",
+	Aliases:      []string{"l", "ls"},
+	Short:        "List rules for a specific app",
+	Long:         `List all rules of a specific app in evaluation order`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		rules, err := client.GetAppRules(appID)
+		if err != nil {
+			return fmt.Errorf("error getting app rules: %v", err)
+		}
+
+		if err := utils.PrintOutput(rules, utils.OutputFormat(appOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var appRulesGetCmd = &cobra.Command{
+	Use:          "get <app-id> <rule-id>",
+	Short:        "Get a rule of a specific app",
+	Long:         `Get a single rule of a specific app`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+		ruleID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid rule ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		rule, err := client.GetAppRule(appID, ruleID)
+		if err != nil {
+			return fmt.Errorf("error getting app rule: %v", err)
+		}
+
+		if err := utils.PrintOutput(rule, utils.OutputFormat(appOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var appRulesCreateCmd = &cobra.Command{
+	Use:   "create <app-id>",
+	Short: "Create rules for a specific app",
+	Long: `Create rules for a specific app from a YAML file.
+The file may contain a single rule or a list of rules, in the same form as 'onecli app rules list' prints them.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+
+		rules, err := readAppRules(appRuleFile)
+		if err != nil {
+			return err
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range rules {
+			id, err := client.CreateAppRule(appID, rule)
+			if err != nil {
+				return fmt.Errorf("error creating app rule %q: %v", rule.Name, err)
+			}
+			fmt.Printf("Successfully created rule %s with ID: %d\n", rule.Name, id)
+		}
+		return nil
+	},
+}
+
+var appRulesDeleteCmd = &cobra.Command{
+	Use:          "delete <app-id> <rule-id>",
+	Aliases:      []string{"rm"},
+	Short:        "Delete a rule of a specific app",
+	Long:         `Delete a single rule of a specific app`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+		ruleID, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid rule ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteAppRule(appID, ruleID); err != nil {
+			return fmt.Errorf("error deleting app rule: %v", err)
+		}
+
+		fmt.Printf("Successfully deleted rule %d from app %d\n", ruleID, appID)
+		return nil
+	},
+}
+
+var appRulesSortCmd = &cobra.Command{
+	Use:          "sort <app-id> <rule-id>...",
+	Short:        "Reorder the rules of a specific app",
+	Long:         `Reorder the rules of a specific app. Every rule ID of the app must be given, in the desired evaluation order.`,
+	Args:         cobra.MinimumNArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+		var ruleIDs []int
+		for _, arg := range args[1:] {
+			ruleID, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("invalid rule ID: %v", err)
+			}
+			ruleIDs = append(ruleIDs, ruleID)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.SortAppRules(appID, ruleIDs); err != nil {
+			return fmt.Errorf("error sorting app rules: %v", err)
+		}
+
+		fmt.Printf("Successfully sorted %d rules of app %d\n", len(ruleIDs), appID)
+		return nil
+	},
+}
+
+// readAppRules reads one rule or a list of rules from a YAML file
+func readAppRules(path string) ([]onelogin.AppRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rule file: %v", err)
+	}
+
+	var rules []onelogin.AppRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		var rule onelogin.AppRule
+		if err := yaml.Unmarshal(data, &rule); err != nil {
+			return nil, fmt.Errorf("error parsing rule file: %v", err)
+		}
+		rules = []onelogin.AppRule{rule}
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules found in %s", path)
+	}
+	return rules, nil
+}
+
 func getAppQuery() onelogin.AppQuery {
 	return onelogin.AppQuery{
 		Name: &appQueryName,
@@ -94,10 +314,23 @@ func getAppQuery() onelogin.AppQuery {
 func init() {
 	appCmd.AddCommand(appListCmd)
 	appCmd.AddCommand(appListUsersCmd)
+	appCmd.AddCommand(appRolesCmd)
+	appCmd.AddCommand(appRulesCmd)
+	appRulesCmd.AddCommand(appRulesListCmd)
+	appRulesCmd.AddCommand(appRulesGetCmd)
+	appRulesCmd.AddCommand(appRulesCreateCmd)
+	appRulesCmd.AddCommand(appRulesDeleteCmd)
+	appRulesCmd.AddCommand(appRulesSortCmd)
 
 	appListCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
 	appListCmd.Flags().StringVar(&appQueryName, "name", "", "Filter apps by name")
 	appListCmd.Flags().BoolVar(&appDetail, "detail", false, "Include user details for each app")
 
 	appListUsersCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	appRolesCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	appRulesListCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	appRulesGetCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+
+	appRulesCreateCmd.Flags().StringVarP(&appRuleFile, "file", "f", "", "YAML file containing the rule(s) to create (required)")
+	_ = appRulesCreateCmd.MarkFlagRequired("file")
 }
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var appParamsCmd = &cobra.Command{
+	Use:     "params",
+	Aliases: []string{"parameters"},
+	Short:   "App parameter management commands",
+	Long:    `Commands for managing app parameters, such as SAML attribute mappings`,
+}
+
+var (
+	appParamsAppsMatching       string
+	appParamsLabel              string
+	appParamsMapping            string
+	appParamsMacro              string
+	appParamsIncludeInAssertion bool
+	appParamsDryRun             bool
+	appParamsYes                bool
+)
+
+var appParamsListCmd = &cobra.Command{
+	Use:          "list <app-id>",
+	Aliases:      []string{"l", "ls"},
+	Short:        "List parameters of a specific app",
+	Long:         `List the parameters (attribute mappings) of a specific app`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid app ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		app, err := client.GetApp(appID)
+		if err != nil {
+			return fmt.Errorf("error getting app: %v", err)
+		}
+
+		if err := utils.PrintOutput(onelogin.AppParameters(app), utils.OutputFormat(appOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var appParamsSetCmd = &cobra.Command{
+	Use:   "set {<app-id> | --apps-matching <name-glob>} <parameter-name>",
+	Short: "Add or update a parameter of apps",
+	Long: `Add or update a parameter of a specific app, or of every app whose name matches --apps-matching.
+A diff of each change is shown and confirmed before it is applied through the app update endpoint.`,
+	Example: `  onecli app params set 123456 department --mapping department --include-in-assertion
+  onecli app params set --apps-matching 'AWS*' department --mapping department`,
+	Args:         appParamsArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		apps, name, err := appParamsTargets(client, args)
+		if err != nil {
+			return err
+		}
+
+		type change struct {
+			app   onelogin.App
+			param onelogin.AppParameter
+		}
+		var changes []change
+		for _, app := range apps {
+			before, exists := onelogin.AppParameter{}, false
+			if app.Parameters != nil {
+				before, exists = (*app.Parameters)[name]
+			}
+
+			after := before
+			if !exists {
+				after = onelogin.AppParameter{Label: name, IncludeInSamlAssertion: true}
+			}
+			if cmd.Flags().Changed("label") {
+				after.Label = appParamsLabel
+			}
+			if cmd.Flags().Changed("mapping") {
+				after.UserAttributeMappings = appParamsMapping
+			}
+			if cmd.Flags().Changed("macro") {
+				after.UserAttributeMacros = appParamsMacro
+			}
+			if cmd.Flags().Changed("include-in-assertion") {
+				after.IncludeInSamlAssertion = appParamsIncludeInAssertion
+			}
+
+			var beforeParam *onelogin.AppParameter
+			if exists {
+				beforeParam = &before
+			}
+			changed, err := printParamDiff(app, name, beforeParam, &after)
+			if err != nil {
+				return err
+			}
+			if changed {
+				changes = append(changes, change{app: app, param: after})
+			}
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("No changes")
+			return nil
+		}
+		if ok, err := confirmParamChanges(len(changes)); err != nil || !ok {
+			return err
+		}
+
+		for _, c := range changes {
+			if err := client.SetAppParameter(c.app, name, c.param); err != nil {
+				return fmt.Errorf("error updating app %d: %v", *c.app.ID, err)
+			}
+			fmt.Printf("Successfully set parameter %s on app %s\n", name, appLabel(c.app))
+		}
+		return nil
+	},
+}
+
+var appParamsRemoveCmd = &cobra.Command{
+	Use:     "remove {<app-id> | --apps-matching <name-glob>} <parameter-name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a parameter from apps",
+	Long: `Remove a parameter from a specific app, or from every app whose name matches --apps-matching.
+A diff of each change is shown and confirmed before it is applied.`,
+	Args:         appParamsArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		apps, name, err := appParamsTargets(client, args)
+		if err != nil {
+			return err
+		}
+
+		var targets []onelogin.App
+		for _, app := range apps {
+			if app.Parameters == nil {
+				continue
+			}
+			before, exists := (*app.Parameters)[name]
+			if !exists {
+				continue
+			}
+			if _, err := printParamDiff(app, name, &before, nil); err != nil {
+				return err
+			}
+			targets = append(targets, app)
+		}
+
+		if len(targets) == 0 {
+			fmt.Println("No changes")
+			return nil
+		}
+		if ok, err := confirmParamChanges(len(targets)); err != nil || !ok {
+			return err
+		}
+
+		for _, app := range targets {
+			if err := client.RemoveAppParameter(app, name); err != nil {
+				return fmt.Errorf("error updating app %d: %v", *app.ID, err)
+			}
+			fmt.Printf("Successfully removed parameter %s from app %s\n", name, appLabel(app))
+		}
+		return nil
+	},
+}
+
+// appParamsArgs accepts <app-id> <parameter-name>, or only <parameter-name>
+// when the apps are selected with --apps-matching
+func appParamsArgs(cmd *cobra.Command, args []string) error {
+	if appParamsAppsMatching != "" {
+		return cobra.ExactArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(2)(cmd, args)
+}
+
+// appParamsTargets resolves the apps to change, with their parameters, and
+// the parameter name from the arguments
+func appParamsTargets(client *onelogin.Onelogin, args []string) ([]onelogin.App, string, error) {
+	if appParamsAppsMatching == "" {
+		appID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid app ID: %v", err)
+		}
+		app, err := client.GetApp(appID)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting app: %v", err)
+		}
+		return []onelogin.App{app}, args[1], nil
+	}
+
+	if _, err := path.Match(appParamsAppsMatching, ""); err != nil {
+		return nil, "", fmt.Errorf("invalid --apps-matching pattern: %v", err)
+	}
+
+	apps, err := client.GetApps(onelogin.AppQuery{})
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting apps: %v", err)
+	}
+
+	var matched []onelogin.App
+	for _, app := range apps {
+		if app.ID == nil || app.Name == nil {
+			continue
+		}
+		if ok, _ := path.Match(appParamsAppsMatching, *app.Name); !ok {
+			continue
+		}
+		// The app list omits parameters, so fetch each app in full
+		detail, err := client.GetApp(int(*app.ID))
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting app %d: %v", *app.ID, err)
+		}
+		matched = append(matched, detail)
+	}
+	if len(matched) == 0 {
+		return nil, "", fmt.Errorf("no apps found matching %q", appParamsAppsMatching)
+	}
+	return matched, args[0], nil
+}
+
+// printParamDiff prints the change of a parameter as a YAML diff. A nil
+// before or after means the parameter does not exist on that side.
+// It reports whether there is any change.
+func printParamDiff(app onelogin.App, name string, before, after *onelogin.AppParameter) (bool, error) {
+	render := func(p *onelogin.AppParameter) (string, error) {
+		if p == nil {
+			return "", nil
+		}
+		b, err := yaml.Marshal(p)
+		if err != nil {
+			return "", fmt.Errorf("error rendering parameter: %v", err)
+		}
+		return string(b), nil
+	}
+
+	beforeText, err := render(before)
+	if err != nil {
+		return false, err
+	}
+	afterText, err := render(after)
+	if err != nil {
+		return false, err
+	}
+
+	diff := utils.Diff(beforeText, afterText)
+	if diff == "" {
+		return false, nil
+	}
+	fmt.Printf("app %s: parameters.%s\n%s\n", appLabel(app), name, diff)
+	return true, nil
+}
+
+// confirmParamChanges asks for confirmation unless --yes or --dry-run is given
+func confirmParamChanges(n int) (bool, error) {
+	if appParamsDryRun {
+		fmt.Printf("Dry run: %d app(s) would be changed\n", n)
+		return false, nil
+	}
+	if appParamsYes {
+		return true, nil
+	}
+
+	fmt.Printf("Apply changes to %d app(s)? [y/N]: ", n)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("error reading confirmation: %v", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		fmt.Println("Aborted")
+		return false, nil
+	}
+	return true, nil
+}
+
+func appLabel(app onelogin.App) string {
+	label := ""
+	if app.ID != nil {
+		label = strconv.Itoa(int(*app.ID))
+	}
+	if app.Name != nil {
+		label += " (" + *app.Name + ")"
+	}
+	return label
+}
+
+func init() {
+	appCmd.AddCommand(appParamsCmd)
+	appParamsCmd.AddCommand(appParamsListCmd)
+	appParamsCmd.AddCommand(appParamsSetCmd)
+	appParamsCmd.AddCommand(appParamsRemoveCmd)
+
+	appParamsListCmd.Flags().StringVarP(&appOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+
+	for _, c := range []*cobra.Command{appParamsSetCmd, appParamsRemoveCmd} {
+		c.Flags().StringVar(&appParamsAppsMatching, "apps-matching", "", "Apply to every app whose name matches this glob pattern instead of a single app ID")
+		c.Flags().BoolVar(&appParamsDryRun, "dry-run", false, "Show the diff without applying it")
+		c.Flags().BoolVarP(&appParamsYes, "yes", "y", false, "Apply without asking for confirmation")
+	}
+
+	appParamsSetCmd.Flags().StringVar(&appParamsLabel, "label", "", "Parameter label (defaults to the parameter name for new parameters)")
+	appParamsSetCmd.Flags().StringVar(&appParamsMapping, "mapping", "", "User attribute mapped to the parameter (e.g. email, department, custom_attribute_xxx)")
+	appParamsSetCmd.Flags().StringVar(&appParamsMacro, "macro", "", "Macro used to build the parameter value (e.g. '{firstname} {lastname}')")
+	appParamsSetCmd.Flags().BoolVar(&appParamsIncludeInAssertion, "include-in-assertion", true, "Include the parameter in the SAML assertion")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noCache  bool
+	cacheTTL time.Duration
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Local cache commands",
+	Long: `Commands for the on-disk cache of reference data such as event types and
+user and app name lookups. The cache lives under the XDG cache directory
+($XDG_CACHE_HOME/onecli, ~/.cache/onecli by default) with one directory per subdomain.`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:          "clear",
+	Short:        "Delete all cached data",
+	Long:         `Delete the cached data of every subdomain`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := utils.CacheDir()
+		if err != nil {
+			return fmt.Errorf("error locating cache directory: %v", err)
+		}
+		if err := utils.NewCache(dir, 0).Clear(); err != nil {
+			return fmt.Errorf("error clearing cache: %v", err)
+		}
+		fmt.Printf("Cleared cache in %s\n", dir)
+		return nil
+	},
+}
+
+// newCache returns the cache of the configured subdomain, or nil if caching
+// is disabled or no cache directory is available
+func newCache() *utils.Cache {
+	subdomain := os.Getenv("ONELOGIN_SUBDOMAIN")
+	if noCache || subdomain == "" || cacheTTL <= 0 {
+		return nil
+	}
+	dir, err := utils.CacheDir()
+	if err != nil {
+		return nil
+	}
+	return utils.NewCache(filepath.Join(dir, subdomain), cacheTTL)
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not read or write the on-disk cache")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", utils.DefaultCacheTTL, "How long cached event types and name lookups stay valid")
+}
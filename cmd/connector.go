@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var connectorCmd = &cobra.Command{
+	Use:   "connector",
+	Short: "Connector catalogue commands",
+	Long:  `Commands for browsing the OneLogin connector catalogue`,
+}
+
+var (
+	connectorQueryName string
+	connectorOutput    string
+)
+
+var connectorListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l", "ls"},
+	Short:   "List connectors",
+	Long: `List connectors in the OneLogin catalogue.
+The connector ID is the connector_id needed to create an app.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		connectors, err := client.GetConnectors(connectorQueryName)
+		if err != nil {
+			return fmt.Errorf("error getting connectors: %v", err)
+		}
+
+		if err := utils.PrintOutput(connectors, utils.OutputFormat(connectorOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	connectorCmd.AddCommand(connectorListCmd)
+
+	connectorListCmd.Flags().StringVarP(&connectorOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	connectorListCmd.Flags().StringVar(&connectorQueryName, "name", "", "Filter connectors by name substring (case-insensitive)")
+}
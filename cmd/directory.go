@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var directoryCmd = &cobra.Command{
+	Use:     "directory",
+	Aliases: []string{"dir"},
+	Short:   "Directory sync commands",
+	Long: `Commands for inspecting directory (AD, LDAP, ...) sync runs.
+They are built from the events of the period given with --since and --until.`,
+}
+
+var (
+	directoryOutput string
+	directorySince  string
+	directoryUntil  string
+)
+
+var directoryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l", "ls"},
+	Short:   "List directories with sync activity",
+	Long: `List the directories that appear in the events of the period, with their
+number of sync runs, events and errors and the latest sync run.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := directoryEventsQuery("")
+		if err != nil {
+			return err
+		}
+
+		directories, err := onelogin.SummarizeDirectories(client.Events(query))
+		if err != nil {
+			return fmt.Errorf("error getting directories: %v", err)
+		}
+
+		if err := utils.PrintOutput(directories, utils.OutputFormat(directoryOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var directoryRunsCmd = &cobra.Command{
+	Use:   "runs <directory-id>",
+	Short: "Summarize the sync runs of a directory",
+	Long: `Summarize each sync run of a directory from its events: the users created,
+updated, suspended, reactivated and deleted, and the errors. Runs are listed newest first.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid directory ID: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := directoryEventsQuery(args[0])
+		if err != nil {
+			return err
+		}
+
+		runs, err := onelogin.SummarizeSyncRuns(client.Events(query))
+		if err != nil {
+			return fmt.Errorf("error getting sync runs: %v", err)
+		}
+
+		if err := utils.PrintOutput(runs, utils.OutputFormat(directoryOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+// directoryEventsQuery returns the query for the events of the period,
+// limited to a directory if directoryID is not empty
+func directoryEventsQuery(directoryID string) (onelogin.EventsQuery, error) {
+	query := onelogin.EventsQuery{}
+	if directoryID != "" {
+		query.DirectoryID = &directoryID
+	}
+
+	now := time.Now()
+	since, err := parseEventTime("--since", directorySince, now)
+	if err != nil {
+		return query, err
+	}
+	query.Since = &since
+	if directoryUntil != "" {
+		until, err := parseEventTime("--until", directoryUntil, now)
+		if err != nil {
+			return query, err
+		}
+		query.Until = &until
+	}
+	return query, nil
+}
+
+func init() {
+	directoryCmd.AddCommand(directoryListCmd)
+	directoryCmd.AddCommand(directoryRunsCmd)
+
+	for _, c := range []*cobra.Command{directoryListCmd, directoryRunsCmd} {
+		c.Flags().StringVarP(&directoryOutput, "output", "o", "yaml", "Output format (yaml, json, csv, xlsx, markdown)")
+		c.Flags().StringVar(&directorySince, "since", "7d", "Only look at events after this time (e.g. 7d, yesterday, 2026-01-02)")
+		c.Flags().StringVar(&directoryUntil, "until", "", "Only look at events before this time")
+	}
+}
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/netip"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pepabo/onecli/onelogin"
 	"github.com/pepabo/onecli/utils"
@@ -28,7 +34,19 @@ var (
 	eventQuerySince       string
 	eventQueryUntil       string
 	eventQueryUserID      string
+	eventQueryUser        string
+	eventQueryApp         string
+	eventQueryIP          string
+	eventQueryRiskMin     int32
 	eventOutput           string
+	eventFollow           bool
+	eventFollowInterval   time.Duration
+	eventExportStateFile  string
+	eventExportLag        time.Duration
+	eventSink             string
+	eventParallel         int
+	eventTypesSearch      string
+	eventTypesIDs         []int32
 )
 
 var eventListCmd = &cobra.Command{
@@ -38,6 +56,206 @@ var eventListCmd = &cobra.Command{
 	Long:         `List all events in your OneLogin organization`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if eventFromArchive != "" {
+			return listArchivedEvents()
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := getEventQuery(client)
+		if err != nil {
+			return err
+		}
+		if eventFollow {
+			if eventParallel > 1 {
+				return fmt.Errorf("--parallel cannot be used with --follow")
+			}
+			return followEvents(cmd, client, query)
+		}
+
+		events := client.Events(query)
+		if eventParallel > 1 {
+			list, err := client.ListEventsParallel(query, eventParallel, time.Now())
+			if err != nil {
+				return fmt.Errorf("error listing events: %v", err)
+			}
+			events = func(yield func(onelogin.Event, error) bool) {
+				for _, e := range list {
+					if !yield(e, nil) {
+						return
+					}
+				}
+			}
+		}
+
+		if eventSink != "" {
+			sink, err := openEventSink(cmd)
+			if err != nil {
+				return err
+			}
+			for e, err := range events {
+				if err != nil {
+					_ = sink.Close()
+					return fmt.Errorf("error listing events: %v", err)
+				}
+				if err := sink.Write(e); err != nil {
+					_ = sink.Close()
+					return err
+				}
+			}
+			return sink.Close()
+		}
+		// Stream the events so the first page is printed while later pages are still being fetched
+		if err := utils.PrintStream(events, utils.OutputFormat(eventOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error listing events: %v", err)
+		}
+		return nil
+	},
+}
+
+var eventTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Follow new events as they arrive",
+	Long: `Poll for new events and print each one as soon as it arrives, one NDJSON line per event.
+Equivalent to 'onecli event list --follow'. Stop with Ctrl-C.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := getEventQuery(client)
+		if err != nil {
+			return err
+		}
+		return followEvents(cmd, client, query)
+	},
+}
+
+// followEvents prints new events as they arrive until SIGINT or SIGTERM
+func followEvents(cmd *cobra.Command, client *onelogin.Onelogin, query onelogin.EventsQuery) error {
+	if eventFollowInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	var (
+		emit func(onelogin.Event) error
+		sink *utils.Sink
+	)
+	if eventSink != "" {
+		var err error
+		if sink, err = openEventSink(cmd); err != nil {
+			return err
+		}
+		emit = func(e onelogin.Event) error { return sink.Write(e) }
+	} else {
+		format, err := eventLineFormat(cmd)
+		if err != nil {
+			return fmt.Errorf("follow mode does not support output format: %v", err)
+		}
+		emit = func(e onelogin.Event) error { return utils.PrintOutput(e, format, os.Stdout) }
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := client.FollowEvents(ctx, query, eventFollowInterval, emit)
+	if sink != nil {
+		// Deliver whatever is still buffered, even after an error
+		if closeErr := sink.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error following events: %v", err)
+	}
+	return nil
+}
+
+// openEventSink opens the --sink target. Syslog sinks send the line format
+// selected with -o (syslog, cef or leef), RFC 5424 syslog by default; HTTP
+// sinks always post JSON batches.
+func openEventSink(cmd *cobra.Command) (*utils.Sink, error) {
+	opts := utils.DefaultSinkOptions()
+	if cmd.Flags().Changed("output") {
+		opts.Format = utils.OutputFormat(outputFlag(cmd))
+	}
+	sink, err := utils.NewSink(eventSink, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sink: %v", err)
+	}
+	return sink, nil
+}
+
+// eventLineFormat returns the one-event-per-line format selected with -o for
+// the commands that keep appending events. json is treated as ndjson.
+func eventLineFormat(cmd *cobra.Command) (utils.OutputFormat, error) {
+	if !cmd.Flags().Changed("output") {
+		return utils.OutputFormatNDJSON, nil
+	}
+	format := utils.OutputFormat(outputFlag(cmd))
+	if format == utils.OutputFormatJSON {
+		return utils.OutputFormatNDJSON, nil
+	}
+	if !utils.IsLineFormat(format) {
+		return "", fmt.Errorf("%s (use ndjson, cef, leef or syslog)", format)
+	}
+	return format, nil
+}
+
+// outputFlag returns the -o value of the running command, which may not be
+// bound to eventOutput
+func outputFlag(cmd *cobra.Command) string {
+	return cmd.Flags().Lookup("output").Value.String()
+}
+
+var eventExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export new events incrementally",
+	Long: `Export events as NDJSON (or CEF, LEEF or syslog lines), saving a checkpoint to --state-file after every page.
+The next run resumes from the checkpoint, so a run that dies halfway neither loses nor repeats events,
+and running it from cron ships each event once. If a run is killed while a page is being written,
+that single page is written again on resume; use the event ID to de-duplicate downstream.
+
+Each run exports up to --lag before now and the next run starts there, so --lag leaves
+time for events that OneLogin stores late. Runs export nothing until --since is more than --lag ago.
+
+--since sets the start of the first run and is ignored once the state file exists.
+Keep the other filters the same between runs that share a state file.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("until") {
+			return fmt.Errorf("--until cannot be used with export; each run exports up to --lag before now")
+		}
+		if eventExportLag < 0 {
+			return fmt.Errorf("--lag must not be negative")
+		}
+		var format utils.OutputFormat
+		if eventSink == "" {
+			f, err := eventLineFormat(cmd)
+			if err != nil {
+				return fmt.Errorf("export does not support output format: %v", err)
+			}
+			format = f
+		}
+
+		state, err := onelogin.LoadExportState(eventExportStateFile)
+		if err != nil {
+			return fmt.Errorf("error reading state file: %v", err)
+		}
+		if state == nil {
+			if eventQuerySince == "" {
+				return fmt.Errorf("--since is required for the first export (no state file at %s)", eventExportStateFile)
+			}
+			since, err := parseEventTime("--since", eventQuerySince, time.Now())
+			if err != nil {
+				return err
+			}
+			state = &onelogin.ExportState{Since: since}
+		}
+
 		client, err := initClient()
 		if err != nil {
 			return err
@@ -46,11 +264,91 @@ var eventListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		events, err := client.ListEvents(query)
+
+		write := func(events []onelogin.Event) error {
+			return utils.PrintOutput(events, format, os.Stdout)
+		}
+		var sink *utils.Sink
+		if eventSink != "" {
+			sink, err = openEventSink(cmd)
+			if err != nil {
+				return err
+			}
+			// Flush every page so the checkpoint only moves past delivered events
+			write = func(events []onelogin.Event) error {
+				for _, e := range events {
+					if err := sink.Write(e); err != nil {
+						return err
+					}
+				}
+				return sink.Flush()
+			}
+		}
+
+		err = client.ExportEvents(query, state, time.Now().Add(-eventExportLag), write, func() error {
+			return state.Save(eventExportStateFile)
+		})
+		if sink != nil {
+			if err != nil {
+				// The checkpoint stays before the failed page and the next run
+				// exports it again, so sending its rest now would duplicate it
+				sink.Discard()
+			}
+			if closeErr := sink.Close(); err == nil && closeErr != nil {
+				return fmt.Errorf("error closing sink: %v", closeErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error exporting events: %v", err)
+		}
+		return nil
+	},
+}
+
+// parseEventTime converts a --since or --until value to the UTC RFC3339 form
+// the events API expects
+func parseEventTime(flag, value string, now time.Time) (string, error) {
+	t, err := utils.ParseTime(value, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %v", flag, err)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+var eventGetCmd = &cobra.Command{
+	Use:   "get <event-id>",
+	Short: "Show a single event with its user, actor, app and role",
+	Long: `Show a single event, expanding the user, actor, app and role it refers to into nested objects.
+CSV and XLSX output list one field per row, e.g. user.email.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventID, err := strconv.Atoi(args[0])
 		if err != nil {
-			return fmt.Errorf("error getting events: %v", err)
+			return fmt.Errorf("invalid event ID: %v", err)
 		}
-		if err := utils.PrintOutput(events, utils.OutputFormat(eventOutput), os.Stdout); err != nil {
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		details, err := client.GetEventDetails(eventID)
+		if err != nil {
+			return fmt.Errorf("error getting event: %v", err)
+		}
+
+		format := utils.OutputFormat(eventOutput)
+		if format == utils.OutputFormatCSV || format == utils.OutputFormatXLSX {
+			rows, err := utils.FlattenRows(details)
+			if err != nil {
+				return fmt.Errorf("error printing output: %v", err)
+			}
+			err = utils.PrintTable(rows, format, os.Stdout)
+		} else {
+			err = utils.PrintOutput(details, format, os.Stdout)
+		}
+		if err != nil {
 			return fmt.Errorf("error printing output: %v", err)
 		}
 		return nil
@@ -58,10 +356,13 @@ var eventListCmd = &cobra.Command{
 }
 
 var eventTypesCmd = &cobra.Command{
-	Use:          "types",
-	Aliases:      []string{"t", "type"},
-	Short:        "List all event types",
-	Long:         `List all event types in your OneLogin organization`,
+	Use:     "types",
+	Aliases: []string{"t", "type"},
+	Short:   "List all event types",
+	Long: `List all event types in your OneLogin organization.
+
+Use --search to find event types whose name or description contains a word,
+and --ids to show only the given event type IDs.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := initClient()
@@ -73,6 +374,7 @@ var eventTypesCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("error getting event types: %v", err)
 		}
+		eventTypes = onelogin.FilterEventTypes(eventTypes, eventTypesSearch, eventTypesIDs)
 
 		if err := utils.PrintOutput(eventTypes, utils.OutputFormat(eventOutput), os.Stdout); err != nil {
 			return fmt.Errorf("error printing output: %v", err)
@@ -104,30 +406,11 @@ func getEventQuery(client *onelogin.Onelogin) (onelogin.EventsQuery, error) {
 	if eventQueryEventTypeID != "" {
 		query.EventTypeID = &eventQueryEventTypeID
 	} else if eventQueryEventType != "" {
-		eventTypes, err := client.GetEventTypes()
+		eventTypeIDs, err := resolveEventTypeIDs(client, eventQueryEventType)
 		if err != nil {
-			return query, fmt.Errorf("error getting event types: %v", err)
+			return query, err
 		}
-		nameToID := onelogin.EventTypeNameIDMap(eventTypes)
-		typeNames := strings.Split(eventQueryEventType, ",")
-		var typeIDs []string
-		var invalidNames []string
-		for _, name := range typeNames {
-			name = strings.TrimSpace(name)
-			if name == "" {
-				continue
-			}
-			if id, exists := nameToID[name]; exists {
-				typeIDs = append(typeIDs, fmt.Sprintf("%d", id))
-			} else {
-				invalidNames = append(invalidNames, name)
-			}
-		}
-		if len(invalidNames) > 0 {
-			return query, fmt.Errorf("invalid event type name(s): %s. Use 'onecli event types' to see available event types", strings.Join(invalidNames, ", "))
-		}
-		if len(typeIDs) > 0 {
-			eventTypeIDs := strings.Join(typeIDs, ",")
+		if eventTypeIDs != "" {
 			query.EventTypeID = &eventTypeIDs
 		}
 	}
@@ -140,39 +423,163 @@ func getEventQuery(client *onelogin.Onelogin) (onelogin.EventsQuery, error) {
 		query.ID = &eventQueryID
 	}
 
+	now := time.Now()
 	if eventQuerySince != "" {
-		query.Since = &eventQuerySince
+		since, err := parseEventTime("--since", eventQuerySince, now)
+		if err != nil {
+			return query, err
+		}
+		query.Since = &since
 	}
 
 	if eventQueryUntil != "" {
-		query.Until = &eventQueryUntil
+		until, err := parseEventTime("--until", eventQueryUntil, now)
+		if err != nil {
+			return query, err
+		}
+		query.Until = &until
+	}
+
+	// RFC3339 in UTC sorts chronologically as a string
+	if query.Since != nil && query.Until != nil && *query.Since > *query.Until {
+		return query, fmt.Errorf("--since (%s) must be before --until (%s)", *query.Since, *query.Until)
 	}
 
 	if eventQueryUserID != "" {
 		query.UserID = &eventQueryUserID
+	} else if eventQueryUser != "" {
+		id, err := client.FindUserID(eventQueryUser)
+		if err != nil {
+			return query, fmt.Errorf("error resolving --user: %v", err)
+		}
+		userID := strconv.Itoa(int(id))
+		query.UserID = &userID
+	}
+
+	if eventQueryApp != "" {
+		appIDs, err := client.FindAppIDs(eventQueryApp)
+		if err != nil {
+			return query, fmt.Errorf("error resolving --app: %v", err)
+		}
+		query.AppIDs = appIDs
+	}
+
+	if eventQueryIP != "" {
+		nets, err := parseIPNets(eventQueryIP)
+		if err != nil {
+			return query, err
+		}
+		query.IPNets = nets
 	}
 
+	query.RiskMin = eventQueryRiskMin
+
 	return query, nil
 }
 
+// resolveEventTypeIDs converts comma-separated event type names into the
+// comma-separated IDs accepted by the events API
+func resolveEventTypeIDs(client *onelogin.Onelogin, names string) (string, error) {
+	eventTypes, err := client.GetEventTypes()
+	if err != nil {
+		return "", fmt.Errorf("error getting event types: %v", err)
+	}
+	nameToID := onelogin.EventTypeNameIDMap(eventTypes)
+	var typeIDs []string
+	var invalidNames []string
+	for name := range strings.SplitSeq(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if id, exists := nameToID[name]; exists {
+			typeIDs = append(typeIDs, fmt.Sprintf("%d", id))
+		} else if suggestions := onelogin.SuggestEventTypeNames(eventTypes, name, 3); len(suggestions) > 0 {
+			invalidNames = append(invalidNames, fmt.Sprintf("%s (did you mean %s?)", name, strings.Join(suggestions, ", ")))
+		} else {
+			invalidNames = append(invalidNames, name)
+		}
+	}
+	if len(invalidNames) > 0 {
+		return "", fmt.Errorf("invalid event type name(s): %s. Use 'onecli event types' to see available event types", strings.Join(invalidNames, ", "))
+	}
+	return strings.Join(typeIDs, ","), nil
+}
+
+// parseIPNets parses a comma-separated list of CIDRs. A bare address matches
+// only itself.
+func parseIPNets(s string) ([]netip.Prefix, error) {
+	var nets []netip.Prefix
+	for v := range strings.SplitSeq(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(v); err == nil {
+			nets = append(nets, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --ip %q: use an IP address or CIDR", v)
+		}
+		nets = append(nets, prefix.Masked())
+	}
+	return nets, nil
+}
+
 func init() {
 	eventCmd.AddCommand(eventListCmd)
+	eventCmd.AddCommand(eventTailCmd)
+	eventCmd.AddCommand(eventExportCmd)
+	eventCmd.AddCommand(eventGetCmd)
 	eventCmd.AddCommand(eventTypesCmd)
 
-	eventListCmd.Flags().StringVarP(&eventOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
-	eventListCmd.Flags().StringVar(&eventQueryClientID, "client-id", "", "Filter events by client ID")
-	eventListCmd.Flags().StringVar(&eventQueryCreatedAt, "created-at", "", "Filter events by created at")
-	eventListCmd.Flags().StringVar(&eventQueryDirectoryID, "directory-id", "", "Filter events by directory ID")
-	eventListCmd.Flags().StringVar(&eventQueryEventTypeID, "type-id", "", "Filter events by event type ID (comma-separated for multiple values)")
-	eventListCmd.Flags().StringVar(&eventQueryEventType, "type", "", "Filter events by event type name (comma-separated for multiple values)")
-	eventListCmd.Flags().StringVar(&eventQueryResolution, "resolution", "", "Filter events by resolution")
-	eventListCmd.Flags().StringVar(&eventQueryID, "id", "", "Filter events by ID")
-	eventListCmd.Flags().StringVar(&eventQuerySince, "since", "", "Filter events from date (YYYY-MM-DD)")
-	eventListCmd.Flags().StringVar(&eventQueryUntil, "until", "", "Filter events to date (YYYY-MM-DD)")
-	eventListCmd.Flags().StringVar(&eventQueryUserID, "user-id", "", "Filter events by user ID")
+	eventListCmd.Flags().StringVarP(&eventOutput, "output", "o", "yaml", "Output format (yaml, json, ndjson, csv, cef, leef, syslog)")
+	addEventQueryFlags(eventListCmd)
+	eventListCmd.Flags().BoolVarP(&eventFollow, "follow", "f", false, "Keep polling and print new events as NDJSON as they arrive")
+	eventListCmd.Flags().DurationVar(&eventFollowInterval, "interval", 10*time.Second, "Polling interval in follow mode")
+	eventListCmd.Flags().IntVar(&eventParallel, "parallel", 1, "Split the --since/--until range into N windows fetched concurrently; results are sorted oldest first")
 
-	// Make --type and --type-id mutually exclusive
-	eventListCmd.MarkFlagsMutuallyExclusive("type", "type-id")
+	eventTailCmd.Flags().StringVarP(&eventOutput, "output", "o", "ndjson", "Output format (ndjson, cef, leef, syslog)")
+	addEventQueryFlags(eventTailCmd)
+	eventTailCmd.Flags().DurationVar(&eventFollowInterval, "interval", 10*time.Second, "Polling interval")
+
+	eventExportCmd.Flags().StringVarP(&eventOutput, "output", "o", "ndjson", "Output format (ndjson, cef, leef, syslog)")
+	addEventQueryFlags(eventExportCmd)
+	eventExportCmd.Flags().StringVar(&eventExportStateFile, "state-file", "", "File to keep the export checkpoint in (required)")
+	eventExportCmd.Flags().DurationVar(&eventExportLag, "lag", onelogin.DefaultExportLag, "End each run this long before now so that late events are exported too")
+	_ = eventExportCmd.MarkFlagRequired("state-file")
+
+	for _, c := range []*cobra.Command{eventListCmd, eventTailCmd, eventExportCmd} {
+		c.Flags().StringVar(&eventSink, "sink", "", "Send events to a collector instead of stdout (syslog+tcp://host:port, syslog+udp://host:port or an http(s):// URL for JSON batches)")
+	}
+
+	eventGetCmd.Flags().StringVarP(&eventOutput, "output", "o", "yaml", "Output format (yaml, json, ndjson, csv, xlsx, cef, leef, syslog)")
 
 	eventTypesCmd.Flags().StringVarP(&eventOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	eventTypesCmd.Flags().StringVar(&eventTypesSearch, "search", "", "Show only event types whose name or description contains this text (case-insensitive)")
+	eventTypesCmd.Flags().Int32SliceVar(&eventTypesIDs, "ids", nil, "Show only these event type IDs (comma-separated)")
+}
+
+// addEventQueryFlags registers the event filter flags read by getEventQuery
+func addEventQueryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&eventQueryClientID, "client-id", "", "Filter events by client ID")
+	cmd.Flags().StringVar(&eventQueryCreatedAt, "created-at", "", "Filter events by created at")
+	cmd.Flags().StringVar(&eventQueryDirectoryID, "directory-id", "", "Filter events by directory ID")
+	cmd.Flags().StringVar(&eventQueryEventTypeID, "type-id", "", "Filter events by event type ID (comma-separated for multiple values)")
+	cmd.Flags().StringVar(&eventQueryEventType, "type", "", "Filter events by event type name (comma-separated for multiple values)")
+	cmd.Flags().StringVar(&eventQueryResolution, "resolution", "", "Filter events by resolution")
+	cmd.Flags().StringVar(&eventQueryID, "id", "", "Filter events by ID")
+	cmd.Flags().StringVar(&eventQuerySince, "since", "", "Filter events from this time: a duration ago (15m, 7d), YYYY-MM-DD, RFC3339, today or yesterday")
+	cmd.Flags().StringVar(&eventQueryUntil, "until", "", "Filter events up to this time: a duration ago (15m, 7d), YYYY-MM-DD, RFC3339, now, today or yesterday")
+	cmd.Flags().StringVar(&eventQueryUserID, "user-id", "", "Filter events by user ID")
+	cmd.Flags().StringVar(&eventQueryUser, "user", "", "Filter events by user email or username")
+	cmd.Flags().StringVar(&eventQueryApp, "app", "", "Filter events by app name")
+	cmd.Flags().StringVar(&eventQueryIP, "ip", "", "Filter events by source IP address or CIDR (comma-separated for multiple values)")
+	cmd.Flags().Int32Var(&eventQueryRiskMin, "risk-min", 0, "Only include events with at least this risk score")
+
+	// Make --type and --type-id mutually exclusive
+	cmd.MarkFlagsMutuallyExclusive("type", "type-id")
+	cmd.MarkFlagsMutuallyExclusive("user", "user-id")
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventArchiveDir       string
+	eventArchiveSince     string
+	eventArchiveRetention string
+	eventArchiveLag       time.Duration
+	eventFromArchive      string
+)
+
+var eventArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Keep a local archive of events",
+	Long: `Save events to --dir as gzip-compressed NDJSON files, one per UTC day
+(YYYY/MM/YYYY-MM-DD.ndjson.gz), with a manifest of the periods covered.
+
+Each run only fetches the parts of the period from --since up to --lag before now
+that the archive does not cover yet, one day at a time, so it can run from cron
+and resume after a failure. A covered period is not fetched again, so --lag leaves
+time for events that OneLogin stores late. Day files older than --retention are deleted.
+
+Query the archive offline with 'onecli event list --from-archive <dir>'; it warns
+about parts of the requested period the archive does not cover.`,
+	Example: `  onecli event archive --dir /var/lib/onecli/events
+  onecli event list --from-archive /var/lib/onecli/events --since 90d --type USER_LOGGED_INTO_ONELOGIN`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		since, err := utils.ParseTime(eventArchiveSince, now)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %v", err)
+		}
+		if eventArchiveLag < 0 {
+			return fmt.Errorf("--lag must not be negative")
+		}
+		var retention time.Duration
+		if eventArchiveRetention != "" {
+			if retention, err = utils.ParseDuration(eventArchiveRetention); err != nil || retention <= 0 {
+				return fmt.Errorf("invalid --retention %q: use a duration like 365d", eventArchiveRetention)
+			}
+		}
+
+		archive, err := onelogin.OpenEventArchive(eventArchiveDir)
+		if err != nil {
+			return fmt.Errorf("error opening archive: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		n, err := client.ArchiveEvents(archive, since, now.Add(-eventArchiveLag), func(r onelogin.ArchiveRange, events int) {
+			fmt.Fprintf(os.Stderr, "Archived %d event(s) from %s to %s\n", events, r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339))
+		})
+		if err != nil {
+			return fmt.Errorf("error archiving events: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Archived %d event(s) in total\n", n)
+
+		if retention > 0 {
+			removed, err := archive.Prune(now.Add(-retention))
+			if err != nil {
+				return fmt.Errorf("error pruning archive: %v", err)
+			}
+			if removed > 0 {
+				fmt.Fprintf(os.Stderr, "Deleted %d day file(s) older than %s\n", removed, eventArchiveRetention)
+			}
+		}
+		return nil
+	},
+}
+
+// listArchivedEvents prints the events of an archive that match the filters
+// of 'event list'. The API is only used to resolve --type, --user and --app.
+func listArchivedEvents() error {
+	if eventFollow || eventParallel > 1 || eventSink != "" {
+		return fmt.Errorf("--from-archive cannot be used with --follow, --parallel or --sink")
+	}
+
+	archive, err := onelogin.OpenEventArchive(eventFromArchive)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %v", err)
+	}
+
+	var client *onelogin.Onelogin
+	if eventQueryEventType != "" || eventQueryUser != "" || eventQueryApp != "" {
+		if client, err = initClient(); err != nil {
+			return err
+		}
+	}
+	query, err := getEventQuery(client)
+	if err != nil {
+		return err
+	}
+
+	uncovered, err := archive.Uncovered(query)
+	if err != nil {
+		return err
+	}
+	if len(archive.Ranges()) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: the archive in %s covers no period yet\n", eventFromArchive)
+	}
+	for _, r := range uncovered {
+		fmt.Fprintf(os.Stderr, "Warning: the archive does not cover %s to %s; results may be incomplete\n",
+			r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339))
+	}
+
+	if err := utils.PrintStream(archive.Events(query), utils.OutputFormat(eventOutput), os.Stdout); err != nil {
+		return fmt.Errorf("error listing events: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	eventCmd.AddCommand(eventArchiveCmd)
+
+	eventArchiveCmd.Flags().StringVar(&eventArchiveDir, "dir", "", "Directory of the archive (required)")
+	eventArchiveCmd.Flags().StringVar(&eventArchiveSince, "since", "30d", "Start of the period to keep archived (e.g. 30d, 2026-01-01)")
+	eventArchiveCmd.Flags().StringVar(&eventArchiveRetention, "retention", "365d", "Delete day files older than this (empty to keep everything)")
+	eventArchiveCmd.Flags().DurationVar(&eventArchiveLag, "lag", onelogin.DefaultArchiveLag, "Stop this long before now so that late events are archived too")
+	_ = eventArchiveCmd.MarkFlagRequired("dir")
+
+	eventListCmd.Flags().StringVar(&eventFromArchive, "from-archive", "", "Read events from an archive directory made by 'event archive' instead of the API")
+}
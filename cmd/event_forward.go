@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventForwardWebhook       string
+	eventForwardSecret        string
+	eventForwardBatch         int
+	eventForwardDeadLetterDir string
+	eventForwardInterval      time.Duration
+)
+
+var eventForwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "Post new events to a webhook",
+	Long: `Follow new events and POST them as JSON to --webhook, one event per request,
+or JSON arrays of up to --batch events.
+
+With $ONECLI_WEBHOOK_SECRET (or --secret) every request is signed: the X-Onecli-Timestamp
+header holds the Unix time and X-Onecli-Signature holds "sha256=" followed by the hex
+HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret. Prefer the environment
+variable, as other users can see --secret in the process list.
+
+Failed requests are retried with backoff. With --dead-letter-dir, requests that still
+fail are saved there instead of stopping the command, and are delivered again on the
+next start. Use --type and the other filters to forward only some events. Stop with Ctrl-C.`,
+	Example: `  ONECLI_WEBHOOK_SECRET=... onecli event forward --webhook https://bot.example.com/onelogin \
+    --type USER_LOCKED,USER_ASSIGNED_ROLE --dead-letter-dir /var/spool/onecli`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !strings.HasPrefix(eventForwardWebhook, "http://") && !strings.HasPrefix(eventForwardWebhook, "https://") {
+			return fmt.Errorf("--webhook must be an http(s) URL")
+		}
+		if eventForwardBatch <= 0 {
+			return fmt.Errorf("--batch must be positive")
+		}
+		if eventForwardInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		opts := utils.DefaultSinkOptions()
+		// --secret takes precedence, but it shows up in the process list
+		if eventForwardSecret != "" {
+			fmt.Fprintln(os.Stderr, "Warning: --secret is visible to other users in the process list; set $ONECLI_WEBHOOK_SECRET instead")
+		}
+		opts.Secret = cmp.Or(eventForwardSecret, os.Getenv("ONECLI_WEBHOOK_SECRET"))
+		opts.Unbatched = eventForwardBatch == 1
+		opts.BatchSize = eventForwardBatch
+		opts.DeadLetterDir = eventForwardDeadLetterDir
+		sink, err := utils.NewSink(eventForwardWebhook, opts)
+		if err != nil {
+			return fmt.Errorf("error opening webhook: %v", err)
+		}
+
+		if n, err := sink.RedeliverDeadLetters(); err != nil {
+			_ = sink.Close()
+			return fmt.Errorf("error redelivering dead letters: %v", err)
+		} else if n > 0 {
+			fmt.Fprintf(os.Stderr, "Redelivered %d event(s) from %s\n", n, eventForwardDeadLetterDir)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			_ = sink.Close()
+			return err
+		}
+		query, err := getEventQuery(client)
+		if err != nil {
+			_ = sink.Close()
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = client.FollowEvents(ctx, query, eventForwardInterval, func(e onelogin.Event) error {
+			return sink.Write(e)
+		})
+		// Deliver whatever is still buffered, even after an error
+		if closeErr := sink.Close(); err == nil {
+			err = closeErr
+		}
+		if n := sink.DeadLetters(); n > 0 {
+			fmt.Fprintf(os.Stderr, "%d event(s) could not be delivered and were saved to %s\n", n, eventForwardDeadLetterDir)
+		}
+		if err != nil {
+			return fmt.Errorf("error forwarding events: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	eventCmd.AddCommand(eventForwardCmd)
+
+	eventForwardCmd.Flags().StringVar(&eventForwardWebhook, "webhook", "", "Webhook URL to post events to (required)")
+	eventForwardCmd.Flags().StringVar(&eventForwardSecret, "secret", "", "Secret for the HMAC-SHA256 signature header (default $ONECLI_WEBHOOK_SECRET)")
+	eventForwardCmd.Flags().IntVar(&eventForwardBatch, "batch", 1, "Post JSON arrays of up to this many events instead of one event per request")
+	eventForwardCmd.Flags().StringVar(&eventForwardDeadLetterDir, "dead-letter-dir", "", "Save events that cannot be delivered to this directory and retry them on the next start")
+	eventForwardCmd.Flags().DurationVar(&eventForwardInterval, "interval", 10*time.Second, "Polling interval")
+	_ = eventForwardCmd.MarkFlagRequired("webhook")
+	addEventQueryFlags(eventForwardCmd)
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventServeListen    string
+	eventServeToken     string
+	eventServeBasicAuth string
+	eventServeNoAuth    bool
+	eventServeTLSCert   string
+	eventServeTLSKey    string
+	eventServeArchive   string
+	eventServeOutput    string
+)
+
+var eventServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Receive events pushed by the OneLogin Event Broadcaster",
+	Long: `Run an HTTP receiver for OneLogin Event Broadcaster webhooks instead of polling the events API.
+
+Configure the broadcaster to POST to this server with a custom header
+"Authorization: Bearer <token>" matching --token (or $ONECLI_SERVE_TOKEN), or with
+basic auth matching --basic-auth user:password. Requests without valid credentials
+are rejected with 401.
+
+Each request may hold a JSON array of events, a single event, or one event per line.
+Event type names are filled in from the event types, and the events are printed in
+the -o line format, sent to --sink, and/or appended to the --archive directory made
+by 'event archive'. A request is answered with 204 only after its events are
+written, so the broadcaster retries the ones that were not.`,
+	Example: `  ONECLI_SERVE_TOKEN=... onecli event serve --listen :8080 --sink syslog+tcp://siem.example.com:514
+  onecli event serve --listen :8443 --tls-cert cert.pem --tls-key key.pem --basic-auth onelogin:secret --archive /var/lib/onecli/events`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if eventServeToken != "" {
+			fmt.Fprintln(os.Stderr, "Warning: --token is visible to other users in the process list; set $ONECLI_SERVE_TOKEN instead")
+		}
+		opts := onelogin.BroadcastOptions{
+			Token: cmp.Or(eventServeToken, os.Getenv("ONECLI_SERVE_TOKEN")),
+		}
+		if eventServeBasicAuth != "" {
+			username, password, ok := strings.Cut(eventServeBasicAuth, ":")
+			if !ok || username == "" {
+				return fmt.Errorf("--basic-auth must be user:password")
+			}
+			opts.Username, opts.Password = username, password
+		}
+		switch {
+		case opts.Token != "" && opts.Username != "":
+			return fmt.Errorf("--token and --basic-auth cannot be used together")
+		case opts.Token == "" && opts.Username == "" && !eventServeNoAuth:
+			return fmt.Errorf("set --token or --basic-auth, or --no-auth to accept unauthenticated requests")
+		}
+		if (eventServeTLSCert == "") != (eventServeTLSKey == "") {
+			return fmt.Errorf("--tls-cert and --tls-key must be used together")
+		}
+
+		var emitters []func([]onelogin.Event) error
+		if eventSink != "" {
+			sink, err := openEventSink(cmd)
+			if err != nil {
+				return err
+			}
+			defer sink.Close()
+			emitters = append(emitters, func(events []onelogin.Event) error {
+				for _, e := range events {
+					if err := sink.Write(e); err != nil {
+						return err
+					}
+				}
+				// Deliver before acknowledging the request
+				return sink.Flush()
+			})
+		}
+		if eventServeArchive != "" {
+			archive, err := onelogin.OpenEventArchive(eventServeArchive)
+			if err != nil {
+				return fmt.Errorf("error opening archive: %v", err)
+			}
+			emitters = append(emitters, archive.Append)
+		}
+		if eventSink == "" && (eventServeArchive == "" || cmd.Flags().Changed("output")) {
+			format, err := eventLineFormat(cmd)
+			if err != nil {
+				return fmt.Errorf("serve does not support output format: %v", err)
+			}
+			emitters = append(emitters, func(events []onelogin.Event) error {
+				return utils.PrintOutput(events, format, os.Stdout)
+			})
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		eventTypes, err := client.GetEventTypes()
+		if err != nil {
+			return fmt.Errorf("error getting event types: %v", err)
+		}
+		opts.EventTypeNames = onelogin.EventTypeIDNameMap(eventTypes)
+
+		// Requests are handled concurrently, so write one batch at a time
+		var mu sync.Mutex
+		handler := onelogin.NewBroadcastHandler(opts, func(events []onelogin.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, emit := range emitters {
+				if err := emit(events); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing events: %v\n", err)
+					return err
+				}
+			}
+			return nil
+		})
+
+		server := &http.Server{
+			Addr:              eventServeListen,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		errc := make(chan error, 1)
+		go func() {
+			if eventServeTLSCert != "" {
+				errc <- server.ListenAndServeTLS(eventServeTLSCert, eventServeTLSKey)
+			} else {
+				errc <- server.ListenAndServe()
+			}
+		}()
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", eventServeListen)
+
+		select {
+		case err := <-errc:
+			return fmt.Errorf("error serving: %v", err)
+		case <-ctx.Done():
+		}
+
+		// Let the requests in progress finish writing their events
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("error shutting down: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	eventCmd.AddCommand(eventServeCmd)
+
+	eventServeCmd.Flags().StringVar(&eventServeListen, "listen", ":8080", "Address to listen on")
+	eventServeCmd.Flags().StringVar(&eventServeToken, "token", "", "Bearer token the broadcaster sends in the Authorization header (default $ONECLI_SERVE_TOKEN)")
+	eventServeCmd.Flags().StringVar(&eventServeBasicAuth, "basic-auth", "", "Basic auth credentials the broadcaster sends, as user:password")
+	eventServeCmd.Flags().BoolVar(&eventServeNoAuth, "no-auth", false, "Accept requests without credentials (only behind a trusted proxy)")
+	eventServeCmd.Flags().StringVar(&eventServeTLSCert, "tls-cert", "", "TLS certificate file to serve HTTPS")
+	eventServeCmd.Flags().StringVar(&eventServeTLSKey, "tls-key", "", "TLS private key file to serve HTTPS")
+	eventServeCmd.Flags().StringVar(&eventServeArchive, "archive", "", "Append received events to this archive directory")
+	eventServeCmd.Flags().StringVarP(&eventServeOutput, "output", "o", "ndjson", "Output format (ndjson, cef, leef, syslog)")
+	eventServeCmd.Flags().StringVar(&eventSink, "sink", "", "Send events to a collector instead of stdout (syslog+tcp://host:port, syslog+udp://host:port or an http(s):// URL for JSON batches)")
+}
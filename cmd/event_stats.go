@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventStatsGroupBy string
+	eventStatsBucket  string
+	eventStatsTop     int
+	eventStatsOutput  string
+)
+
+var eventStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Count events per group",
+	Long: `Aggregate events and print the count, first seen and last seen time of each group.
+Groups are made from the event fields given with --group-by, using their JSON names
+(e.g. event_type, user_name, ipaddr, app_name). --bucket additionally splits the counts
+into time buckets for a simple histogram.`,
+	Example: `  onecli event stats --group-by event_type,user_name --since 7d --top 10
+  onecli event stats --type USER_FAILED_AUTHENTICATION --group-by ipaddr --bucket 1h --since 1d -o csv`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := onelogin.EventStatsOptions{Top: eventStatsTop}
+		for name := range strings.SplitSeq(eventStatsGroupBy, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.GroupBy = append(opts.GroupBy, name)
+			}
+		}
+		if eventStatsBucket != "" {
+			bucket, err := utils.ParseDuration(eventStatsBucket)
+			if err != nil || bucket <= 0 {
+				return fmt.Errorf("invalid --bucket %q: use a duration like 1h or 1d", eventStatsBucket)
+			}
+			opts.Bucket = bucket
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := getEventQuery(client)
+		if err != nil {
+			return err
+		}
+
+		stats, err := onelogin.AggregateEvents(client.Events(query), opts)
+		if err != nil {
+			return fmt.Errorf("error aggregating events: %v", err)
+		}
+
+		format := utils.OutputFormat(eventStatsOutput)
+		if format == utils.OutputFormatCSV || format == utils.OutputFormatXLSX {
+			err = utils.PrintTable(onelogin.EventStatsRows(stats, opts.GroupBy, opts.Bucket > 0), format, os.Stdout)
+		} else {
+			err = utils.PrintOutput(stats, format, os.Stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	eventCmd.AddCommand(eventStatsCmd)
+
+	eventStatsCmd.Flags().StringVarP(&eventStatsOutput, "output", "o", "yaml", "Output format (yaml, json, ndjson, csv, xlsx)")
+	eventStatsCmd.Flags().StringVar(&eventStatsGroupBy, "group-by", "event_type", "Comma-separated event fields to group by")
+	eventStatsCmd.Flags().StringVar(&eventStatsBucket, "bucket", "", "Split the counts into time buckets of this size (e.g. 1h, 1d)")
+	eventStatsCmd.Flags().IntVar(&eventStatsTop, "top", 0, "Only show the N groups with the most events")
+	addEventQueryFlags(eventStatsCmd)
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"net/netip"
 	"testing"
+	"time"
 
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,3 +71,48 @@ func TestEventTypeNameValidation(t *testing.T) {
 		assert.NotEmpty(t, name)
 	}
 }
+
+func TestParseEventTime(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseEventTime("--since", "2026-10-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-01T00:00:00Z", got)
+
+	got, err = parseEventTime("--since", "2026-10-01T09:00:00+09:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-01T00:00:00Z", got)
+
+	got, err = parseEventTime("--since", "7d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-12T12:00:00Z", got)
+
+	_, err = parseEventTime("--until", "yesterday-ish", now)
+	assert.ErrorContains(t, err, "invalid --until")
+}
+
+func TestParseIPNets(t *testing.T) {
+	nets, err := parseIPNets("192.0.2.1, 198.51.100.7/24,2001:db8::/32")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("198.51.100.0/24"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, nets)
+
+	_, err = parseIPNets("192.0.2.0/33")
+	assert.ErrorContains(t, err, "invalid --ip")
+}
+
+func TestEventOutputDefaults(t *testing.T) {
+	// Commands that share eventOutput must not change each other's default
+	assert.Equal(t, "yaml", eventOutput)
+	for _, c := range []*cobra.Command{eventListCmd, eventGetCmd, eventTypesCmd} {
+		assert.Equal(t, "yaml", outputFlag(c), c.Name())
+	}
+	for _, c := range []*cobra.Command{eventTailCmd, eventExportCmd, eventServeCmd} {
+		format, err := eventLineFormat(c)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.OutputFormatNDJSON, format, c.Name())
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventWatchRules    string
+	eventWatchSink     string
+	eventWatchInterval time.Duration
+	eventWatchGeoIP    string
+)
+
+var eventWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Raise alerts from rules over the event stream",
+	Long: `Follow new events and evaluate the alert rules in --rules against each one.
+Alerts are printed as NDJSON, or posted as JSON batches to the --sink webhook.
+
+Each rule selects events with 'match' (event field to glob pattern or list of patterns), and raises
+an alert for every matching event, or when a 'threshold' count is reached within a sliding window,
+or when a 'new_value' of a field appears for a group. Windows and seen values are kept in memory,
+so they start empty on every run; a new_value group with no event for its 'expire' (default 30d)
+is forgotten. With --geoip (CSV columns: network,country,city,latitude,longitude) rules can also
+use the 'country' and 'city' of the event's IP address. Stop with Ctrl-C.
+
+Example rules file:
+
+  rules:
+    - name: brute-force
+      description: More than 10 failed logins in 5 minutes
+      severity: high
+      match:
+        event_type: USER_FAILED_AUTHENTICATION
+      threshold:
+        count: 11
+        window: 5m
+        group_by: [user_name]
+    - name: admin-role-assigned
+      match:
+        event_type: USER_ASSIGNED_ROLE
+        role_name: [Super user, Account owner]
+    - name: new-ip-for-user
+      match:
+        event_type: USER_LOGGED_INTO_ONELOGIN
+      new_value:
+        field: ipaddr
+        group_by: [user_name]
+    - name: new-country-for-user
+      match:
+        event_type: USER_LOGGED_INTO_ONELOGIN
+      new_value:
+        field: country
+        group_by: [user_name]
+        expire: 90d`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var geoIP utils.GeoIPDB
+		if eventWatchGeoIP != "" {
+			table, err := utils.LoadGeoIPCSV(eventWatchGeoIP)
+			if err != nil {
+				return fmt.Errorf("error loading GeoIP database: %v", err)
+			}
+			geoIP = table
+		}
+		engine, err := onelogin.LoadAlertRules(eventWatchRules, geoIP)
+		if err != nil {
+			return fmt.Errorf("error loading rules: %v", err)
+		}
+		if eventWatchInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		emit := func(alert onelogin.Alert) error {
+			return utils.PrintOutput(alert, utils.OutputFormatNDJSON, os.Stdout)
+		}
+		var sink *utils.Sink
+		if eventWatchSink != "" {
+			if !strings.HasPrefix(eventWatchSink, "http://") && !strings.HasPrefix(eventWatchSink, "https://") {
+				return fmt.Errorf("--sink must be an http(s) webhook URL")
+			}
+			if sink, err = utils.NewSink(eventWatchSink, utils.DefaultSinkOptions()); err != nil {
+				return fmt.Errorf("error opening sink: %v", err)
+			}
+			emit = func(alert onelogin.Alert) error { return sink.Write(alert) }
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		query, err := getEventQuery(client)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = client.FollowEvents(ctx, query, eventWatchInterval, func(e onelogin.Event) error {
+			for _, alert := range engine.Process(e) {
+				if err := emit(alert); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if sink != nil {
+			if closeErr := sink.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error watching events: %v", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	eventCmd.AddCommand(eventWatchCmd)
+
+	eventWatchCmd.Flags().StringVar(&eventWatchRules, "rules", "", "Alert rules file (YAML, required)")
+	eventWatchCmd.Flags().StringVar(&eventWatchSink, "sink", "", "Post alerts as JSON batches to this webhook URL instead of printing them")
+	eventWatchCmd.Flags().DurationVar(&eventWatchInterval, "interval", 10*time.Second, "Polling interval")
+	eventWatchCmd.Flags().StringVar(&eventWatchGeoIP, "geoip", "", "GeoIP CSV file for the country and city fields")
+	_ = eventWatchCmd.MarkFlagRequired("rules")
+	addEventQueryFlags(eventWatchCmd)
+}
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report commands",
+	Long:  `Commands for generating reports about your OneLogin organization`,
+}
+
+var (
+	reportOutput        string
+	reportCertWithin    string
+	reportCertQueryName string
+
+	reportMatrixOutput     string
+	reportMatrixAppName    string
+	reportMatrixUserStatus int32
+	reportMatrixMarker     string
+
+	reportLoginOutput        string
+	reportLoginSince         string
+	reportLoginUntil         string
+	reportLoginBaseline      string
+	reportLoginTypes         string
+	reportLoginRiskThreshold int32
+	reportLoginFailures      int
+	reportLoginFailureWindow time.Duration
+	reportLoginMaxSpeed      float64
+	reportLoginGeoIP         string
+	reportLoginAll           bool
+
+	reportAdminOutput string
+	reportAdminMonth  string
+	reportAdminTypes  []string
+)
+
+// certExpiry is a row of the cert-expiry report
+type certExpiry struct {
+	AppID           int32      `json:"app_id"`
+	AppName         string     `json:"app_name"`
+	CertificateName string     `json:"certificate_name,omitempty"`
+	Subject         string     `json:"subject,omitempty"`
+	NotAfter        *time.Time `json:"not_after,omitempty"`
+	DaysRemaining   *int       `json:"days_remaining,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+var reportCertExpiryCmd = &cobra.Command{
+	Use:   "cert-expiry",
+	Short: "Report SAML certificate expiry of apps",
+	Long: `List the SAML signing certificate of every app sorted by expiry.
+Exits with a non-zero status if any certificate expires within the --within window,
+so that it can be run as a scheduled check.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		within, err := utils.ParseDuration(reportCertWithin)
+		if err != nil {
+			return fmt.Errorf("invalid --within: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		certs, err := client.GetAppCertificates(onelogin.AppQuery{Name: &reportCertQueryName})
+		if err != nil {
+			return fmt.Errorf("error getting app certificates: %v", err)
+		}
+
+		rows, expiring, failed := certExpiryReport(certs, time.Now(), within)
+		for _, r := range rows {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "Warning: app %d (%s): %s\n", r.AppID, r.AppName, r.Error)
+			}
+		}
+
+		if err := utils.PrintOutput(rows, utils.OutputFormat(reportOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+
+		switch {
+		case expiring > 0:
+			return fmt.Errorf("%d certificate(s) expire within %s", expiring, reportCertWithin)
+		case failed > 0:
+			return fmt.Errorf("%d app certificate(s) could not be checked", failed)
+		}
+		return nil
+	},
+}
+
+// certExpiryReport builds the report rows and counts the certificates
+// expiring before now+within (already expired ones included) and the apps
+// whose certificate could not be read
+func certExpiryReport(certs []onelogin.AppCertificate, now time.Time, within time.Duration) ([]certExpiry, int, int) {
+	rows := make([]certExpiry, 0, len(certs))
+	expiring, failed := 0, 0
+	deadline := now.Add(within)
+	for _, c := range certs {
+		row := certExpiry{
+			AppID:           c.AppID,
+			AppName:         c.AppName,
+			CertificateName: c.CertificateName,
+			Subject:         c.Subject,
+			Error:           c.Error,
+		}
+		if c.Error != "" {
+			failed++
+			rows = append(rows, row)
+			continue
+		}
+		days := int(c.NotAfter.Sub(now).Hours() / 24)
+		row.NotAfter = &c.NotAfter
+		row.DaysRemaining = &days
+		rows = append(rows, row)
+		if c.NotAfter.Before(deadline) {
+			expiring++
+		}
+	}
+	return rows, expiring, failed
+}
+
+var reportAccessMatrixCmd = &cobra.Command{
+	Use:   "access-matrix",
+	Short: "Report which users can access which apps",
+	Long: `Build a user × app grid with one column per app and a marker in each cell
+where the user is assigned to the app. Output is CSV or XLSX.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := utils.OutputFormat(reportMatrixOutput)
+		if format != utils.OutputFormatCSV && format != utils.OutputFormatXLSX {
+			return fmt.Errorf("unsupported output format: %s (use csv or xlsx)", reportMatrixOutput)
+		}
+		if reportMatrixAppName != "" {
+			if _, err := path.Match(reportMatrixAppName, ""); err != nil {
+				return fmt.Errorf("invalid --app-name pattern: %v", err)
+			}
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		apps, err := client.GetAppsDetails(onelogin.AppQuery{})
+		if err != nil {
+			return fmt.Errorf("error getting apps: %v", err)
+		}
+
+		if reportMatrixAppName != "" {
+			apps = slices.DeleteFunc(apps, func(app onelogin.AppDetails) bool {
+				if app.Name == nil {
+					return true
+				}
+				matched, _ := path.Match(reportMatrixAppName, *app.Name)
+				return !matched
+			})
+		}
+
+		matrix := onelogin.NewAccessMatrix(apps)
+
+		// App users do not carry their status, so look it up from the user list
+		if cmd.Flags().Changed("user-status") {
+			users, err := client.GetUsers(onelogin.UserQuery{})
+			if err != nil {
+				return fmt.Errorf("error getting users: %v", err)
+			}
+			status := make(map[int32]int32, len(users))
+			for _, u := range users {
+				status[u.ID] = u.Status
+			}
+			matrix.FilterUsers(func(u onelogin.User) bool {
+				return status[u.ID] == reportMatrixUserStatus
+			})
+		}
+
+		if err := utils.PrintTable(matrix.Rows(reportMatrixMarker), format, os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var reportLoginAnomaliesCmd = &cobra.Command{
+	Use:   "login-anomalies",
+	Short: "Report suspicious logins per user",
+	Long: `Group login events per user and flag, within the --since window:
+  - successful logins from an IP, browser fingerprint or country not seen before
+  - consecutive logins too far apart to travel between (impossible travel)
+  - logins with a risk score of at least --risk-threshold
+  - --failures failed logins within --failure-window
+
+Logins in the --baseline period before --since are the known IPs, fingerprints
+and countries that new ones are compared with.
+
+Countries and impossible travel need --geoip, a CSV file read offline with the
+columns network,country,city,latitude,longitude (e.g. 203.0.113.0/24,JP,Tokyo,35.68,139.69).
+
+Only users with anomalies are listed unless --all is given.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := utils.OutputFormat(reportLoginOutput)
+		now := time.Now()
+		since, err := utils.ParseTime(reportLoginSince, now)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %v", err)
+		}
+		until := now
+		if reportLoginUntil != "" {
+			if until, err = utils.ParseTime(reportLoginUntil, now); err != nil {
+				return fmt.Errorf("invalid --until: %v", err)
+			}
+		}
+		if !since.Before(until) {
+			return fmt.Errorf("--since must be before --until")
+		}
+		baseline, err := utils.ParseDuration(reportLoginBaseline)
+		if err != nil {
+			return fmt.Errorf("invalid --baseline: %v", err)
+		}
+
+		opts := onelogin.LoginAnomalyOptions{
+			Since:         since,
+			RiskThreshold: reportLoginRiskThreshold,
+			FailureCount:  reportLoginFailures,
+			FailureWindow: reportLoginFailureWindow,
+			MaxSpeedKmh:   reportLoginMaxSpeed,
+		}
+		if reportLoginGeoIP != "" {
+			geoip, err := utils.LoadGeoIPCSV(reportLoginGeoIP)
+			if err != nil {
+				return fmt.Errorf("error loading GeoIP database: %v", err)
+			}
+			opts.GeoIP = geoip
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		typeIDs, err := resolveEventTypeIDs(client, reportLoginTypes)
+		if err != nil {
+			return err
+		}
+		start := since.Add(-baseline).UTC().Format(time.RFC3339)
+		end := until.UTC().Format(time.RFC3339)
+		query := onelogin.EventsQuery{Since: &start, Until: &end}
+		if typeIDs != "" {
+			query.EventTypeID = &typeIDs
+		}
+
+		events, err := client.ListEvents(query)
+		if err != nil {
+			return fmt.Errorf("error getting events: %v", err)
+		}
+
+		reports := onelogin.DetectLoginAnomalies(events, opts)
+		if !reportLoginAll {
+			reports = slices.DeleteFunc(reports, func(r onelogin.LoginUserReport) bool {
+				return len(r.Anomalies) == 0
+			})
+		}
+
+		switch format {
+		case utils.OutputFormatCSV, utils.OutputFormatXLSX:
+			err = utils.PrintTable(onelogin.LoginAnomalyRows(reports), format, os.Stdout)
+		default:
+			err = utils.PrintOutput(reports, format, os.Stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+var reportAdminActivityCmd = &cobra.Command{
+	Use:   "admin-activity",
+	Short: "Report changes made by administrators in a month",
+	Long: `List the administrative changes of a month grouped by actor, for compliance reviews.
+
+An event is included when its actor differs from the user it is about (someone
+acted on another user), or when its event type matches one of the --types glob
+patterns, which by default cover user, role, privilege, policy and app changes.
+
+Output is CSV with one row per change, or a Markdown summary that can be
+attached to a ticket.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month := reportAdminMonth
+		if month == "" {
+			month = previousMonth(time.Now())
+		}
+		since, until, err := utils.ParseMonth(month)
+		if err != nil {
+			return fmt.Errorf("invalid --month: %v", err)
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+
+		start, end := since.Format(time.RFC3339), until.Format(time.RFC3339)
+		events := client.Events(onelogin.EventsQuery{Since: &start, Until: &end})
+		report, err := onelogin.CollectAdminActivity(events, reportAdminTypes, since, until)
+		if err != nil {
+			return fmt.Errorf("error collecting admin activity: %v", err)
+		}
+
+		switch format := utils.OutputFormat(reportAdminOutput); format {
+		case utils.OutputFormatMarkdown:
+			err = report.WriteMarkdown(os.Stdout)
+		case utils.OutputFormatCSV, utils.OutputFormatXLSX:
+			err = utils.PrintTable(report.Rows(), format, os.Stdout)
+		default:
+			err = utils.PrintOutput(report, format, os.Stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+// previousMonth returns the UTC month before now as YYYY-MM. It steps back
+// from the first day of the month, as AddDate on the 29th to 31st can land
+// in the same month.
+func previousMonth(now time.Time) string {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+}
+
+func init() {
+	reportCmd.AddCommand(reportCertExpiryCmd)
+	reportCmd.AddCommand(reportAccessMatrixCmd)
+	reportCmd.AddCommand(reportLoginAnomaliesCmd)
+	reportCmd.AddCommand(reportAdminActivityCmd)
+
+	reportCertExpiryCmd.Flags().StringVarP(&reportOutput, "output", "o", "yaml", "Output format (yaml, json, csv)")
+	reportCertExpiryCmd.Flags().StringVar(&reportCertWithin, "within", "30d", "Fail if a certificate expires within this duration (e.g. 60d, 2w, 12h)")
+	reportCertExpiryCmd.Flags().StringVar(&reportCertQueryName, "name", "", "Filter apps by name")
+
+	reportAccessMatrixCmd.Flags().StringVarP(&reportMatrixOutput, "output", "o", "csv", "Output format (csv, xlsx)")
+	reportAccessMatrixCmd.Flags().StringVar(&reportMatrixAppName, "app-name", "", "Only include apps whose name matches this glob pattern (e.g. 'AWS*')")
+	reportAccessMatrixCmd.Flags().Int32Var(&reportMatrixUserStatus, "user-status", 0, "Only include users with this status (1=Active, 2=Suspended, 4=PasswordExpired, 5=AwaitingPasswordReset)")
+	reportAccessMatrixCmd.Flags().StringVar(&reportMatrixMarker, "marker", "x", "Marker written in cells where the user can access the app")
+
+	reportLoginAnomaliesCmd.Flags().StringVarP(&reportLoginOutput, "output", "o", "yaml", "Output format (yaml, json, csv, xlsx)")
+	reportLoginAnomaliesCmd.Flags().StringVar(&reportLoginSince, "since", "7d", "Start of the reported window (e.g. 7d, yesterday, 2026-01-02)")
+	reportLoginAnomaliesCmd.Flags().StringVar(&reportLoginUntil, "until", "", "End of the reported window (default now)")
+	reportLoginAnomaliesCmd.Flags().StringVar(&reportLoginBaseline, "baseline", "30d", "Period before --since whose logins are taken as known")
+	reportLoginAnomaliesCmd.Flags().StringVar(&reportLoginTypes, "type", strings.Join(onelogin.DefaultLoginEventTypes, ","), "Login event type names (comma-separated)")
+	reportLoginAnomaliesCmd.Flags().Int32Var(&reportLoginRiskThreshold, "risk-threshold", 75, "Flag logins with at least this risk score (0 to disable)")
+	reportLoginAnomaliesCmd.Flags().IntVar(&reportLoginFailures, "failures", 5, "Flag this many failed logins within --failure-window (0 to disable)")
+	reportLoginAnomaliesCmd.Flags().DurationVar(&reportLoginFailureWindow, "failure-window", 10*time.Minute, "Window for counting failed logins")
+	reportLoginAnomaliesCmd.Flags().Float64Var(&reportLoginMaxSpeed, "max-speed", 900, "Fastest plausible travel between logins in km/h (0 to disable)")
+	reportLoginAnomaliesCmd.Flags().StringVar(&reportLoginGeoIP, "geoip", "", "GeoIP CSV file for country and impossible travel checks")
+	reportLoginAnomaliesCmd.Flags().BoolVar(&reportLoginAll, "all", false, "Include users without anomalies")
+
+	reportAdminActivityCmd.Flags().StringVarP(&reportAdminOutput, "output", "o", "csv", "Output format (csv, markdown, xlsx, yaml, json)")
+	reportAdminActivityCmd.Flags().StringVar(&reportAdminMonth, "month", "", "Month to report as YYYY-MM (default last month, UTC)")
+	reportAdminActivityCmd.Flags().StringSliceVar(&reportAdminTypes, "types", onelogin.DefaultAdminEventTypes, "Glob patterns of event type names counted as admin changes (comma-separated)")
+}
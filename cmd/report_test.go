@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertExpiryReport(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	certs := []onelogin.AppCertificate{
+		{AppID: 1, AppName: "Expired", NotAfter: now.AddDate(0, 0, -3)},
+		{AppID: 2, AppName: "Soon", NotAfter: now.AddDate(0, 0, 10)},
+		{AppID: 3, AppName: "Later", NotAfter: now.AddDate(0, 0, 90)},
+		{AppID: 4, AppName: "Broken", Error: "error reading certificate"},
+	}
+
+	rows, expiring, failed := certExpiryReport(certs, now, 60*24*time.Hour)
+
+	assert.Equal(t, 2, expiring)
+	assert.Equal(t, 1, failed)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, -3, *rows[0].DaysRemaining)
+	assert.Equal(t, 10, *rows[1].DaysRemaining)
+	assert.Equal(t, 90, *rows[2].DaysRemaining)
+	assert.Nil(t, rows[3].DaysRemaining)
+	assert.Nil(t, rows[3].NotAfter)
+	assert.Equal(t, "error reading certificate", rows[3].Error)
+}
+
+func TestPreviousMonth(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		expected string
+	}{
+		{now: time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC), expected: "2026-09"},
+		{now: time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC), expected: "2026-02"},
+		{now: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), expected: "2025-12"},
+		// Still the 31st of May in UTC
+		{now: time.Date(2026, 6, 1, 8, 0, 0, 0, time.FixedZone("JST", 9*60*60)), expected: "2026-04"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, previousMonth(tt.now), tt.now.String())
+	}
+}
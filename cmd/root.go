@@ -38,7 +38,11 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(userCmd)
 	rootCmd.AddCommand(appCmd)
+	rootCmd.AddCommand(connectorCmd)
+	rootCmd.AddCommand(directoryCmd)
 	rootCmd.AddCommand(eventCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(cacheCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 }
@@ -34,6 +34,9 @@ func initClient() (*onelogin.Onelogin, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error initializing OneLogin client: %v", err)
 	}
+	if cache := newCache(); cache != nil {
+		client.SetCache(cache)
+	}
 	return client, nil
 }
 
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pepabo/onecli/onelogin"
+	"github.com/pepabo/onecli/utils"
+	"github.com/spf13/cobra"
+)
+
+// outputFormatText is the human-readable timeline, the default of user timeline
+const outputFormatText = "text"
+
+var (
+	userTimelineOutput string
+	userTimelineSince  string
+	userTimelineUntil  string
+)
+
+var userTimelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Show a timeline of a user's events",
+	Long: `Show the events of a user in chronological order, one line per event with the
+event type, app, IP address and resolution, grouped by UTC day.
+
+Failed logins, errors and lockouts are marked with "!" (and shown in red on a
+terminal unless NO_COLOR is set). Use --output json for tooling.`,
+	Example: `  onecli user timeline --email alice@example.com --since 3d
+  onecli user timeline --user-id 123 --since 2026-10-01 --until 2026-10-03 --output json`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := getUserQuery()
+		if isQueryParamsEmpty(query) {
+			return fmt.Errorf("at least one query parameter (email, username, firstname, lastname, or user-id) must be specified")
+		}
+
+		now := time.Now()
+		since, err := parseEventTime("--since", userTimelineSince, now)
+		if err != nil {
+			return err
+		}
+		eventsQuery := onelogin.EventsQuery{Since: &since}
+		if userTimelineUntil != "" {
+			until, err := parseEventTime("--until", userTimelineUntil, now)
+			if err != nil {
+				return err
+			}
+			eventsQuery.Until = &until
+		}
+
+		client, err := initClient()
+		if err != nil {
+			return err
+		}
+		user, err := findUserByQuery(client, query)
+		if err != nil {
+			return err
+		}
+		userID := strconv.Itoa(int(user.ID))
+		eventsQuery.UserID = &userID
+
+		entries, err := onelogin.BuildUserTimeline(client.Events(eventsQuery))
+		if err != nil {
+			return fmt.Errorf("error getting events: %v", err)
+		}
+
+		if userTimelineOutput == outputFormatText {
+			fmt.Printf("%s (user %d)\n\n", user.Email, user.ID)
+			if err := onelogin.WriteTimeline(os.Stdout, entries, useColor(os.Stdout)); err != nil {
+				return fmt.Errorf("error printing output: %v", err)
+			}
+			return nil
+		}
+		if err := utils.PrintOutput(entries, utils.OutputFormat(userTimelineOutput), os.Stdout); err != nil {
+			return fmt.Errorf("error printing output: %v", err)
+		}
+		return nil
+	},
+}
+
+// useColor reports whether f is a terminal and NO_COLOR is not set
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	userCmd.AddCommand(userTimelineCmd)
+
+	userTimelineCmd.Flags().StringVarP(&userTimelineOutput, "output", "o", outputFormatText, "Output format (text, json, ndjson, yaml, csv)")
+	userTimelineCmd.Flags().StringVar(&userQueryEmail, "email", "", "Query by email")
+	userTimelineCmd.Flags().StringVar(&userQueryUsername, "username", "", "Query by username")
+	userTimelineCmd.Flags().StringVar(&userQueryFirstname, "firstname", "", "Query by first name")
+	userTimelineCmd.Flags().StringVar(&userQueryLastname, "lastname", "", "Query by last name")
+	userTimelineCmd.Flags().StringVar(&userQueryUserID, "user-id", "", "Query by user ID")
+	userTimelineCmd.Flags().StringVar(&userTimelineSince, "since", "3d", "Show events after this time (e.g. 3d, yesterday, 2026-01-02)")
+	userTimelineCmd.Flags().StringVar(&userTimelineUntil, "until", "", "Show events before this time")
+}
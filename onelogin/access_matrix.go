@@ -0,0 +1,96 @@
+package onelogin
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+)
+
+// AccessMatrix is a user × app grid of who can access which app
+type AccessMatrix struct {
+	Apps   []App
+	Users  []User
+	access map[int32]map[int32]bool
+}
+
+// NewAccessMatrix builds an AccessMatrix from apps and their assigned users.
+// Apps are ordered by name and users by email so the grid is stable across runs.
+func NewAccessMatrix(apps []AppDetails) *AccessMatrix {
+	m := &AccessMatrix{
+		access: make(map[int32]map[int32]bool),
+	}
+
+	seen := make(map[int32]bool)
+	for _, app := range apps {
+		if app.ID == nil {
+			continue
+		}
+		m.Apps = append(m.Apps, app.App)
+		for _, user := range app.Users {
+			if m.access[user.ID] == nil {
+				m.access[user.ID] = make(map[int32]bool)
+			}
+			m.access[user.ID][*app.ID] = true
+			if !seen[user.ID] {
+				seen[user.ID] = true
+				m.Users = append(m.Users, user)
+			}
+		}
+	}
+
+	slices.SortStableFunc(m.Apps, func(a, b App) int {
+		return cmp.Compare(appName(a), appName(b))
+	})
+	slices.SortStableFunc(m.Users, func(a, b User) int {
+		return cmp.Compare(a.Email, b.Email)
+	})
+
+	return m
+}
+
+// HasAccess reports whether the user is assigned to the app
+func (m *AccessMatrix) HasAccess(userID, appID int32) bool {
+	return m.access[userID][appID]
+}
+
+// FilterUsers keeps only the users for which keep returns true
+func (m *AccessMatrix) FilterUsers(keep func(User) bool) {
+	m.Users = slices.DeleteFunc(m.Users, func(u User) bool {
+		return !keep(u)
+	})
+}
+
+// Rows renders the matrix as a table with a header row. Each cell of an app
+// column holds marker if the user can access the app and is empty otherwise.
+func (m *AccessMatrix) Rows(marker string) [][]string {
+	header := []string{"user_id", "email", "username", "name"}
+	for _, app := range m.Apps {
+		header = append(header, appName(app))
+	}
+
+	rows := [][]string{header}
+	for _, user := range m.Users {
+		row := []string{
+			strconv.Itoa(int(user.ID)),
+			user.Email,
+			user.Username,
+			user.Firstname + " " + user.Lastname,
+		}
+		for _, app := range m.Apps {
+			cell := ""
+			if m.HasAccess(user.ID, *app.ID) {
+				cell = marker
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func appName(app App) string {
+	if app.Name == nil {
+		return ""
+	}
+	return *app.Name
+}
@@ -0,0 +1,44 @@
+package onelogin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessMatrix(t *testing.T) {
+	id1, id2 := int32(1), int32(2)
+	slack, github := "Slack", "GitHub"
+
+	apps := []AppDetails{
+		{
+			App: App{ID: &id1, Name: &slack},
+			Users: []User{
+				{ID: 10, Email: "bob@example.com", Username: "bob", Firstname: "Bob", Lastname: "B"},
+				{ID: 20, Email: "alice@example.com", Username: "alice", Firstname: "Alice", Lastname: "A"},
+			},
+		},
+		{
+			App: App{ID: &id2, Name: &github},
+			Users: []User{
+				{ID: 20, Email: "alice@example.com", Username: "alice", Firstname: "Alice", Lastname: "A"},
+			},
+		},
+	}
+
+	m := NewAccessMatrix(apps)
+
+	assert.True(t, m.HasAccess(10, 1))
+	assert.False(t, m.HasAccess(10, 2))
+	assert.Equal(t, [][]string{
+		{"user_id", "email", "username", "name", "GitHub", "Slack"},
+		{"20", "alice@example.com", "alice", "Alice A", "x", "x"},
+		{"10", "bob@example.com", "bob", "Bob B", "", "x"},
+	}, m.Rows("x"))
+
+	m.FilterUsers(func(u User) bool { return u.ID == 10 })
+	assert.Equal(t, [][]string{
+		{"user_id", "email", "username", "name", "GitHub", "Slack"},
+		{"10", "bob@example.com", "bob", "Bob B", "", "x"},
+	}, m.Rows("x"))
+}
@@ -0,0 +1,205 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+)
+
+// DefaultAdminEventTypes are glob patterns of event type names that are
+// administrative changes even when the actor is not recorded
+var DefaultAdminEventTypes = []string{
+	"USER_CREATED",
+	"USER_DELETED",
+	"USER_UPDATED",
+	"USER_SUSPENDED",
+	"USER_UNSUSPENDED",
+	"USER_LOCKED",
+	"USER_UNLOCKED",
+	"*ROLE*",
+	"*PRIVILEGE*",
+	"*POLICY*",
+	"APP_*",
+	"*MAPPING*",
+	"*CONNECTOR*",
+	"*CERTIFICATE*",
+	"*DIRECTORY*",
+	"*API_CREDENTIAL*",
+}
+
+// AdminActivity is the administrative changes made by one actor
+type AdminActivity struct {
+	ActorUserID int32          `json:"actor_user_id,omitempty"`
+	ActorName   string         `json:"actor_name"`
+	Count       int            `json:"count"`
+	EventTypes  map[string]int `json:"event_types"`
+	Events      []Event        `json:"events"`
+}
+
+// AdminActivityReport is the administrative changes in a period grouped by actor
+type AdminActivityReport struct {
+	Since  time.Time       `json:"since"`
+	Until  time.Time       `json:"until"`
+	Actors []AdminActivity `json:"actors"`
+}
+
+// isAdminEvent reports whether an event is a change made by an administrator:
+// either someone acted on another user, or the event type matches one of the
+// patterns
+func isAdminEvent(e Event, patterns []string) bool {
+	if e.ActorUserID != 0 && e.ActorUserID != e.UserID {
+		return true
+	}
+	return slices.ContainsFunc(patterns, func(p string) bool {
+		ok, _ := path.Match(p, e.EventType)
+		return ok
+	})
+}
+
+// CollectAdminActivity picks the administrative changes out of the stream
+// and groups them by actor. Actors are sorted by number of changes, most
+// first, and each actor's events oldest first.
+func CollectAdminActivity(events iter.Seq2[Event, error], patterns []string, since, until time.Time) (*AdminActivityReport, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid event type pattern %q", p)
+		}
+	}
+
+	actors := map[string]*AdminActivity{}
+	for e, err := range events {
+		if err != nil {
+			return nil, err
+		}
+		if !isAdminEvent(e, patterns) {
+			continue
+		}
+
+		key, name := "system:"+e.ActorSystem, cmp.Or(e.ActorSystem, "(unknown)")
+		if e.ActorUserID != 0 {
+			key, name = strconv.Itoa(int(e.ActorUserID)), cmp.Or(e.ActorUserName, strconv.Itoa(int(e.ActorUserID)))
+		}
+		a, ok := actors[key]
+		if !ok {
+			a = &AdminActivity{ActorUserID: e.ActorUserID, ActorName: name, EventTypes: map[string]int{}}
+			actors[key] = a
+		}
+		a.Count++
+		a.EventTypes[e.EventType]++
+		a.Events = append(a.Events, e)
+	}
+
+	report := &AdminActivityReport{Since: since.UTC(), Until: until.UTC(), Actors: []AdminActivity{}}
+	for _, a := range actors {
+		SortEventsChronologically(a.Events)
+		report.Actors = append(report.Actors, *a)
+	}
+	slices.SortFunc(report.Actors, func(a, b AdminActivity) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.ActorName, b.ActorName))
+	})
+	return report, nil
+}
+
+// adminEventTarget describes what an event acted on
+func adminEventTarget(e Event) string {
+	var parts []string
+	if e.UserName != "" {
+		parts = append(parts, "user "+e.UserName)
+	}
+	if e.AppName != "" {
+		parts = append(parts, "app "+e.AppName)
+	}
+	if e.RoleName != "" {
+		parts = append(parts, "role "+e.RoleName)
+	}
+	if e.GroupName != "" {
+		parts = append(parts, "group "+e.GroupName)
+	}
+	if e.PolicyName != "" {
+		parts = append(parts, "policy "+e.PolicyName)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatEventTime(e Event) string {
+	if e.CreatedAt == nil {
+		return ""
+	}
+	return e.CreatedAt.UTC().Format(time.RFC3339)
+}
+
+// Rows returns one row per event for CSV and XLSX output
+func (r *AdminActivityReport) Rows() [][]string {
+	rows := [][]string{{"actor_user_id", "actor_name", "time", "event_type", "target", "ipaddr", "event_id", "message"}}
+	for _, a := range r.Actors {
+		for _, e := range a.Events {
+			rows = append(rows, []string{
+				strconv.Itoa(int(a.ActorUserID)),
+				a.ActorName,
+				formatEventTime(e),
+				e.EventType,
+				adminEventTarget(e),
+				e.IPAddr,
+				strconv.FormatUint(e.ID, 10),
+				cmp.Or(e.CustomMessage, e.Notes),
+			})
+		}
+	}
+	return rows
+}
+
+// WriteMarkdown writes the report as a Markdown document with a summary table
+// and a section per actor, to be attached to a ticket
+func (r *AdminActivityReport) WriteMarkdown(w io.Writer) error {
+	total := 0
+	for _, a := range r.Actors {
+		total += a.Count
+	}
+
+	fmt.Fprintf(w, "# Admin activity %s – %s\n\n", r.Since.Format(time.DateOnly), r.Until.Add(-time.Second).Format(time.DateOnly))
+	fmt.Fprintf(w, "%d change(s) by %d actor(s).\n", total, len(r.Actors))
+	if len(r.Actors) == 0 {
+		return nil
+	}
+
+	summary := [][]string{{"Actor", "Changes", "Event types"}}
+	for _, a := range r.Actors {
+		types := slices.SortedFunc(maps.Keys(a.EventTypes), func(x, y string) int {
+			return cmp.Or(cmp.Compare(a.EventTypes[y], a.EventTypes[x]), cmp.Compare(x, y))
+		})
+		for i, t := range types {
+			types[i] = fmt.Sprintf("%s (%d)", t, a.EventTypes[t])
+		}
+		summary = append(summary, []string{a.ActorName, strconv.Itoa(a.Count), strings.Join(types, ", ")})
+	}
+	fmt.Fprintf(w, "\n## Summary\n\n")
+	if err := utils.WriteMarkdownTable(summary, w); err != nil {
+		return err
+	}
+
+	for _, a := range r.Actors {
+		heading := a.ActorName
+		if a.ActorUserID != 0 {
+			heading += fmt.Sprintf(" (user %d)", a.ActorUserID)
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", heading)
+
+		rows := [][]string{{"Time", "Event type", "Target", "IP address", "Event ID"}}
+		for _, e := range a.Events {
+			rows = append(rows, []string{formatEventTime(e), e.EventType, adminEventTarget(e), e.IPAddr, strconv.FormatUint(e.ID, 10)})
+		}
+		if err := utils.WriteMarkdownTable(rows, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
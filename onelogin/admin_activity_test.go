@@ -0,0 +1,94 @@
+package onelogin
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectAdminActivity(t *testing.T) {
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 1, 0)
+	at := func(hours int) *time.Time {
+		t := since.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	events := []Event{
+		// Newest first, as the API returns them
+		{ID: 6, CreatedAt: at(6), EventType: "USER_LOGGED_INTO_ONELOGIN", UserID: 2, ActorUserID: 2},
+		{ID: 5, CreatedAt: at(5), EventType: "APP_UPDATED", ActorUserID: 1, ActorUserName: "alice", AppName: "Slack"},
+		{ID: 4, CreatedAt: at(4), EventType: "USER_DELETED", ActorSystem: "API", UserName: "carol"},
+		{ID: 3, CreatedAt: at(3), EventType: "USER_ASSIGNED_ROLE", UserID: 2, UserName: "bob", ActorUserID: 1, ActorUserName: "alice", RoleName: "Admin"},
+		{ID: 2, CreatedAt: at(2), EventType: "USER_CHANGED_PASSWORD", UserID: 3, ActorUserID: 3},
+		{ID: 1, CreatedAt: at(1), EventType: "USER_RESET_PASSWORD", UserID: 2, UserName: "bob", ActorUserID: 4, ActorUserName: "dave"},
+	}
+
+	report, err := CollectAdminActivity(eventSeq(events), DefaultAdminEventTypes, since, until)
+	require.NoError(t, err)
+	assert.Equal(t, since, report.Since)
+	assert.Equal(t, until, report.Until)
+
+	require.Len(t, report.Actors, 3)
+	alice := report.Actors[0]
+	assert.Equal(t, int32(1), alice.ActorUserID)
+	assert.Equal(t, "alice", alice.ActorName)
+	assert.Equal(t, 2, alice.Count)
+	assert.Equal(t, map[string]int{"APP_UPDATED": 1, "USER_ASSIGNED_ROLE": 1}, alice.EventTypes)
+	assert.Equal(t, []uint64{3, 5}, []uint64{alice.Events[0].ID, alice.Events[1].ID})
+
+	assert.Equal(t, "API", report.Actors[1].ActorName)
+	assert.Equal(t, "dave", report.Actors[2].ActorName)
+
+	t.Run("fetch error", func(t *testing.T) {
+		seq := func(yield func(Event, error) bool) {
+			yield(Event{}, errors.New("boom"))
+		}
+		_, err := CollectAdminActivity(seq, nil, since, until)
+		assert.EqualError(t, err, "boom")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := CollectAdminActivity(eventSeq(nil), []string{"["}, since, until)
+		assert.Error(t, err)
+	})
+}
+
+func TestAdminActivityReportOutput(t *testing.T) {
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	at := since.Add(time.Hour)
+	report := &AdminActivityReport{
+		Since: since,
+		Until: since.AddDate(0, 1, 0),
+		Actors: []AdminActivity{{
+			ActorUserID: 1,
+			ActorName:   "alice",
+			Count:       1,
+			EventTypes:  map[string]int{"USER_ASSIGNED_ROLE": 1},
+			Events: []Event{{
+				ID: 3, CreatedAt: &at, EventType: "USER_ASSIGNED_ROLE", UserName: "bob", RoleName: "Admin", IPAddr: "192.0.2.1",
+			}},
+		}},
+	}
+
+	rows := report.Rows()
+	require.Len(t, rows, 2)
+	assert.Equal(t, []string{"1", "alice", "2026-09-01T01:00:00Z", "USER_ASSIGNED_ROLE", "user bob, role Admin", "192.0.2.1", "3", ""}, rows[1])
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&buf))
+	md := buf.String()
+	assert.Contains(t, md, "# Admin activity 2026-09-01 – 2026-09-30\n")
+	assert.Contains(t, md, "1 change(s) by 1 actor(s).")
+	assert.Contains(t, md, "| alice | 1 | USER_ASSIGNED_ROLE (1) |")
+	assert.Contains(t, md, "## alice (user 1)")
+	assert.Contains(t, md, "| 2026-09-01T01:00:00Z | USER_ASSIGNED_ROLE | user bob, role Admin | 192.0.2.1 | 3 |")
+
+	buf.Reset()
+	empty := &AdminActivityReport{Since: report.Since, Until: report.Until}
+	require.NoError(t, empty.WriteMarkdown(&buf))
+	assert.Equal(t, "# Admin activity 2026-09-01 – 2026-09-30\n\n0 change(s) by 0 actor(s).\n", buf.String())
+}
@@ -0,0 +1,377 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/netip"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pepabo/onecli/utils"
+)
+
+// AlertRules is the rules file read by 'event watch'
+type AlertRules struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// AlertRule is a detection evaluated against each event.
+//
+// Match selects the events the rule looks at by event field (JSON name), each
+// with one or more glob patterns. Without Threshold or NewValue every matching
+// event raises an alert. Besides the event fields, rules can use the country
+// and city of the event's IP address when the engine has a GeoIP database.
+type AlertRule struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Severity    string                    `json:"severity,omitempty"`
+	Match       map[string]AlertRuleValue `json:"match,omitempty"`
+	Threshold   *AlertThreshold           `json:"threshold,omitempty"`
+	NewValue    *AlertNewValue            `json:"new_value,omitempty"`
+}
+
+// AlertThreshold raises an alert when Count matching events with the same
+// GroupBy values fall within Window
+type AlertThreshold struct {
+	Count   int      `json:"count"`
+	Window  string   `json:"window"`
+	GroupBy []string `json:"group_by,omitempty"`
+}
+
+// AlertNewValue raises an alert when Field has a value not seen before for
+// the same GroupBy values. The first value of each group is the baseline and
+// does not raise an alert. A group that has no matching event for Expire
+// (DefaultAlertNewValueExpire if empty) is forgotten and starts over.
+type AlertNewValue struct {
+	Field   string   `json:"field"`
+	GroupBy []string `json:"group_by,omitempty"`
+	Expire  string   `json:"expire,omitempty"`
+}
+
+// DefaultAlertNewValueExpire is how long the values of an idle new_value
+// group are remembered
+const DefaultAlertNewValueExpire = "30d"
+
+// Fields derived from the event's IP address with a GeoIP database
+const (
+	AlertFieldCountry = "country"
+	AlertFieldCity    = "city"
+)
+
+// AlertRuleValue is a single pattern or a list of patterns in a rules file
+type AlertRuleValue []string
+
+// UnmarshalYAML accepts both a scalar and a list
+func (v *AlertRuleValue) UnmarshalYAML(b []byte) error {
+	var list []string
+	if err := yaml.Unmarshal(b, &list); err == nil {
+		*v = list
+		return nil
+	}
+	var single string
+	if err := yaml.Unmarshal(b, &single); err != nil {
+		return err
+	}
+	*v = AlertRuleValue{single}
+	return nil
+}
+
+// Alert is raised when an event triggers a rule
+type Alert struct {
+	Rule     string            `json:"rule"`
+	Severity string            `json:"severity,omitempty"`
+	Message  string            `json:"message"`
+	Time     time.Time         `json:"time"`
+	Group    map[string]string `json:"group,omitempty"`
+	Count    int               `json:"count,omitempty"`
+	EventIDs []uint64          `json:"event_ids"`
+}
+
+// LoadAlertRules reads and validates a rules file. geoIP resolves the
+// country and city fields and may be nil when no rule uses them.
+func LoadAlertRules(file string, geoIP utils.GeoIPDB) (*AlertEngine, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules AlertRules
+	if err := yaml.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %v", file, err)
+	}
+	return NewAlertEngine(rules.Rules, geoIP)
+}
+
+// AlertEngine evaluates rules against a stream of events, keeping the state
+// of threshold windows and seen values in memory
+type AlertEngine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	AlertRule
+	geoIP  utils.GeoIPDB
+	window time.Duration
+	// windows holds the matching events of each group within the window
+	windows map[string][]Event
+	// expire is how long an idle new_value group is remembered
+	expire time.Duration
+	// seen holds the values of NewValue.Field seen for each group
+	seen map[string]*seenValues
+	// pruned is the event time state was last cleaned up at
+	pruned time.Time
+}
+
+// seenValues is the values seen for a new_value group
+type seenValues struct {
+	values map[string]bool
+	last   time.Time
+}
+
+// NewAlertEngine validates the rules and returns an engine for them. geoIP
+// resolves the country and city fields and may be nil when no rule uses them.
+func NewAlertEngine(rules []AlertRule, geoIP utils.GeoIPDB) (*AlertEngine, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no rules defined")
+	}
+
+	engine := &AlertEngine{}
+	names := map[string]bool{}
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("rule %s: duplicate name", r.Name)
+		}
+		names[r.Name] = true
+
+		if r.Threshold != nil && r.NewValue != nil {
+			return nil, fmt.Errorf("rule %s: threshold and new_value cannot be used together", r.Name)
+		}
+
+		fields := []string{}
+		for field, patterns := range r.Match {
+			fields = append(fields, field)
+			for _, p := range patterns {
+				if _, err := path.Match(p, ""); err != nil {
+					return nil, fmt.Errorf("rule %s: invalid pattern %q for %s", r.Name, p, field)
+				}
+			}
+		}
+
+		c := compiledRule{AlertRule: r, geoIP: geoIP}
+		if t := r.Threshold; t != nil {
+			if t.Count <= 0 {
+				return nil, fmt.Errorf("rule %s: threshold count must be positive", r.Name)
+			}
+			window, err := utils.ParseDuration(t.Window)
+			if err != nil || window <= 0 {
+				return nil, fmt.Errorf("rule %s: invalid threshold window %q", r.Name, t.Window)
+			}
+			c.window = window
+			c.windows = map[string][]Event{}
+			fields = append(fields, t.GroupBy...)
+		}
+		if n := r.NewValue; n != nil {
+			if n.Field == "" {
+				return nil, fmt.Errorf("rule %s: new_value field is required", r.Name)
+			}
+			expire, err := utils.ParseDuration(cmp.Or(n.Expire, DefaultAlertNewValueExpire))
+			if err != nil || expire <= 0 {
+				return nil, fmt.Errorf("rule %s: invalid new_value expire %q", r.Name, n.Expire)
+			}
+			c.expire = expire
+			c.seen = map[string]*seenValues{}
+			fields = append(fields, n.Field)
+			fields = append(fields, n.GroupBy...)
+		}
+
+		for _, field := range fields {
+			if field == AlertFieldCountry || field == AlertFieldCity {
+				if geoIP == nil {
+					return nil, fmt.Errorf("rule %s: field %q requires a GeoIP database", r.Name, field)
+				}
+				continue
+			}
+			if _, ok := eventFieldIndex[field]; !ok {
+				return nil, fmt.Errorf("rule %s: unknown event field %q", r.Name, field)
+			}
+		}
+		engine.rules = append(engine.rules, c)
+	}
+	return engine, nil
+}
+
+// Process evaluates every rule against an event and returns the alerts it
+// raises. Events are expected oldest first, as FollowEvents delivers them.
+func (a *AlertEngine) Process(e Event) []Alert {
+	var alerts []Alert
+	for i := range a.rules {
+		if alert := a.rules[i].process(e); alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts
+}
+
+// fieldValue returns the value of an event field, or of a field derived
+// from the IP address with the GeoIP database
+func (r *compiledRule) fieldValue(e Event, field string) string {
+	if field != AlertFieldCountry && field != AlertFieldCity {
+		return eventFieldValue(e, field)
+	}
+	addr, err := netip.ParseAddr(e.IPAddr)
+	if err != nil {
+		return ""
+	}
+	location, ok := r.geoIP.Lookup(addr)
+	if !ok {
+		return ""
+	}
+	if field == AlertFieldCity {
+		return location.City
+	}
+	return location.Country
+}
+
+func (r *compiledRule) matches(e Event) bool {
+	for field, patterns := range r.Match {
+		value := r.fieldValue(e, field)
+		if !slices.ContainsFunc(patterns, func(p string) bool {
+			ok, _ := path.Match(p, value)
+			return ok
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *compiledRule) process(e Event) *Alert {
+	if !r.matches(e) {
+		return nil
+	}
+
+	at := time.Now().UTC()
+	if e.CreatedAt != nil {
+		at = e.CreatedAt.UTC()
+	}
+	r.prune(at)
+
+	switch {
+	case r.Threshold != nil:
+		key, group := r.groupKey(e, r.Threshold.GroupBy)
+
+		// Slide the window forward and drop events that fell out of it
+		events := slices.DeleteFunc(append(r.windows[key], e), func(w Event) bool {
+			return w.CreatedAt != nil && !w.CreatedAt.After(at.Add(-r.window))
+		})
+		if len(events) < r.Threshold.Count {
+			r.windows[key] = events
+			return nil
+		}
+		// Start over so the same burst raises a single alert
+		delete(r.windows, key)
+
+		ids := make([]uint64, len(events))
+		for i, w := range events {
+			ids[i] = w.ID
+		}
+		return r.alert(at, group, len(events), ids,
+			fmt.Sprintf("%d events within %s", len(events), r.Threshold.Window))
+
+	case r.NewValue != nil:
+		key, group := r.groupKey(e, r.NewValue.GroupBy)
+		value := r.fieldValue(e, r.NewValue.Field)
+		if value == "" {
+			return nil
+		}
+		seen, ok := r.seen[key]
+		if !ok || seen.expired(at, r.expire) {
+			r.seen[key] = &seenValues{values: map[string]bool{value: true}, last: at}
+			return nil
+		}
+		seen.last = at
+		if seen.values[value] {
+			return nil
+		}
+		seen.values[value] = true
+		return r.alert(at, group, 1, []uint64{e.ID},
+			fmt.Sprintf("new %s %s", r.NewValue.Field, value))
+
+	default:
+		return r.alert(at, nil, 1, []uint64{e.ID}, e.EventType)
+	}
+}
+
+// prune drops the groups whose window or expiry has passed, so that state
+// does not grow with every group ever seen. It runs at most once per window
+// or expiry of event time.
+func (r *compiledRule) prune(at time.Time) {
+	switch {
+	case r.Threshold != nil && at.Sub(r.pruned) >= r.window:
+		for key, events := range r.windows {
+			last := events[len(events)-1].CreatedAt
+			if last != nil && !last.After(at.Add(-r.window)) {
+				delete(r.windows, key)
+			}
+		}
+	case r.NewValue != nil && at.Sub(r.pruned) >= r.expire:
+		for key, seen := range r.seen {
+			if seen.expired(at, r.expire) {
+				delete(r.seen, key)
+			}
+		}
+	default:
+		return
+	}
+	r.pruned = at
+}
+
+// expired reports whether the group has had no event for expire
+func (s *seenValues) expired(at time.Time, expire time.Duration) bool {
+	return !s.last.After(at.Add(-expire))
+}
+
+func (r *compiledRule) alert(at time.Time, group map[string]string, count int, ids []uint64, detail string) *Alert {
+	message := r.Name + ": " + detail
+	if r.Description != "" {
+		message = r.Description + ": " + detail
+	}
+	if len(group) > 0 {
+		var parts []string
+		for _, k := range slices.Sorted(maps.Keys(group)) {
+			parts = append(parts, k+"="+strconv.Quote(group[k]))
+		}
+		message += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return &Alert{
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Message:  message,
+		Time:     at,
+		Group:    group,
+		Count:    count,
+		EventIDs: ids,
+	}
+}
+
+// groupKey returns the key of the event's group and the group's field values
+func (r *compiledRule) groupKey(e Event, groupBy []string) (string, map[string]string) {
+	if len(groupBy) == 0 {
+		return "", nil
+	}
+	values := make([]string, len(groupBy))
+	group := make(map[string]string, len(groupBy))
+	for i, field := range groupBy {
+		values[i] = r.fieldValue(e, field)
+		group[field] = values[i]
+	}
+	return strings.Join(values, "\x00"), group
+}
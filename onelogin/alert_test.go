@@ -0,0 +1,211 @@
+package onelogin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAlertRules = `
+rules:
+  - name: brute-force
+    description: Too many failed logins
+    severity: high
+    match:
+      event_type: USER_FAILED_AUTHENTICATION
+    threshold:
+      count: 3
+      window: 5m
+      group_by: [user_name]
+  - name: admin-role
+    match:
+      event_type: [USER_ASSIGNED_ROLE, ROLE_ADDED_*]
+      role_name: Super*
+  - name: new-ip
+    match:
+      event_type: USER_LOGGED_INTO_ONELOGIN
+    new_value:
+      field: ipaddr
+      group_by: [user_name]
+`
+
+func TestLoadAlertRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testAlertRules), 0o600))
+
+	engine, err := LoadAlertRules(file, nil)
+	require.NoError(t, err)
+	require.Len(t, engine.rules, 3)
+	assert.Equal(t, AlertRuleValue{"USER_FAILED_AUTHENTICATION"}, engine.rules[0].Match["event_type"])
+	assert.Equal(t, AlertRuleValue{"USER_ASSIGNED_ROLE", "ROLE_ADDED_*"}, engine.rules[1].Match["event_type"])
+	assert.Equal(t, 5*time.Minute, engine.rules[0].window)
+}
+
+func TestAlertEngine(t *testing.T) {
+	base := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	event := func(id uint64, minutes int, eventType, user string) Event {
+		at := base.Add(time.Duration(minutes) * time.Minute)
+		return Event{ID: id, CreatedAt: &at, EventType: eventType, UserName: user}
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		engine, err := NewAlertEngine([]AlertRule{{
+			Name:      "brute-force",
+			Match:     map[string]AlertRuleValue{"event_type": {"USER_FAILED_AUTHENTICATION"}},
+			Threshold: &AlertThreshold{Count: 3, Window: "5m", GroupBy: []string{"user_name"}},
+		}}, nil)
+		require.NoError(t, err)
+
+		var alerts []Alert
+		for _, e := range []Event{
+			event(1, 0, "USER_FAILED_AUTHENTICATION", "alice"),
+			event(2, 1, "USER_FAILED_AUTHENTICATION", "alice"),
+			event(3, 2, "USER_FAILED_AUTHENTICATION", "bob"),
+			event(4, 3, "USER_LOGGED_INTO_ONELOGIN", "alice"),
+			// Events 1 and 2 leave the window as it slides
+			event(5, 5, "USER_FAILED_AUTHENTICATION", "alice"),
+			event(6, 6, "USER_FAILED_AUTHENTICATION", "alice"),
+			event(7, 6, "USER_FAILED_AUTHENTICATION", "alice"),
+			// The window starts over after an alert
+			event(8, 7, "USER_FAILED_AUTHENTICATION", "alice"),
+		} {
+			alerts = append(alerts, engine.Process(e)...)
+		}
+
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "brute-force", alerts[0].Rule)
+		assert.Equal(t, 3, alerts[0].Count)
+		assert.Equal(t, []uint64{5, 6, 7}, alerts[0].EventIDs)
+		assert.Equal(t, map[string]string{"user_name": "alice"}, alerts[0].Group)
+		assert.Equal(t, `brute-force: 3 events within 5m (user_name="alice")`, alerts[0].Message)
+		assert.Equal(t, base.Add(6*time.Minute), alerts[0].Time)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		engine, err := NewAlertEngine([]AlertRule{{
+			Name:        "admin-role",
+			Description: "Admin role assigned",
+			Match:       map[string]AlertRuleValue{"event_type": {"USER_ASSIGNED_ROLE"}, "role_name": {"Super*"}},
+		}}, nil)
+		require.NoError(t, err)
+
+		e := event(1, 0, "USER_ASSIGNED_ROLE", "alice")
+		assert.Empty(t, engine.Process(e))
+
+		e.RoleName = "Super user"
+		alerts := engine.Process(e)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "Admin role assigned: USER_ASSIGNED_ROLE", alerts[0].Message)
+	})
+
+	t.Run("new value", func(t *testing.T) {
+		engine, err := NewAlertEngine([]AlertRule{{
+			Name:     "new-ip",
+			NewValue: &AlertNewValue{Field: "ipaddr", GroupBy: []string{"user_name"}},
+		}}, nil)
+		require.NoError(t, err)
+
+		withIP := func(e Event, ip string) Event {
+			e.IPAddr = ip
+			return e
+		}
+		var alerts []Alert
+		for _, e := range []Event{
+			withIP(event(1, 0, "LOGIN", "alice"), "192.0.2.1"),
+			withIP(event(2, 1, "LOGIN", "alice"), "192.0.2.1"),
+			withIP(event(3, 2, "LOGIN", "bob"), "192.0.2.9"),
+			withIP(event(4, 3, "LOGIN", "alice"), "198.51.100.1"),
+		} {
+			alerts = append(alerts, engine.Process(e)...)
+		}
+
+		require.Len(t, alerts, 1)
+		assert.Equal(t, []uint64{4}, alerts[0].EventIDs)
+		assert.Equal(t, `new-ip: new ipaddr 198.51.100.1 (user_name="alice")`, alerts[0].Message)
+	})
+}
+
+func TestAlertEngineNewCountry(t *testing.T) {
+	geoIP, err := utils.ParseGeoIPCSV(strings.NewReader("network,country,city,latitude,longitude\n192.0.2.0/24,JP,Tokyo,35.68,139.69\n198.51.100.0/24,US,New York,40.71,-74.01\n"))
+	require.NoError(t, err)
+
+	engine, err := NewAlertEngine([]AlertRule{{
+		Name:     "new-country",
+		Match:    map[string]AlertRuleValue{"event_type": {"USER_LOGGED_INTO_ONELOGIN"}},
+		NewValue: &AlertNewValue{Field: "country", GroupBy: []string{"user_name"}, Expire: "7d"},
+	}}, geoIP)
+	require.NoError(t, err)
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	login := func(id uint64, days int, ip string) Event {
+		at := base.AddDate(0, 0, days)
+		return Event{ID: id, CreatedAt: &at, EventType: "USER_LOGGED_INTO_ONELOGIN", UserName: "alice", IPAddr: ip}
+	}
+
+	var alerts []Alert
+	for _, e := range []Event{
+		login(1, 0, "192.0.2.1"),
+		login(2, 1, "192.0.2.7"),
+		login(3, 2, "203.0.113.1"), // not in the database
+		login(4, 3, "198.51.100.1"),
+		// Idle for longer than expire, so the group starts over
+		login(5, 20, "192.0.2.1"),
+		login(6, 21, "198.51.100.1"),
+	} {
+		alerts = append(alerts, engine.Process(e)...)
+	}
+
+	require.Len(t, alerts, 2)
+	assert.Equal(t, []uint64{4}, alerts[0].EventIDs)
+	assert.Equal(t, `new-country: new country US (user_name="alice")`, alerts[0].Message)
+	assert.Equal(t, []uint64{6}, alerts[1].EventIDs)
+}
+
+func TestAlertEnginePrune(t *testing.T) {
+	engine, err := NewAlertEngine([]AlertRule{
+		{Name: "burst", Threshold: &AlertThreshold{Count: 5, Window: "5m", GroupBy: []string{"user_name"}}},
+		{Name: "new-ip", NewValue: &AlertNewValue{Field: "ipaddr", GroupBy: []string{"user_name"}, Expire: "1d"}},
+	}, nil)
+	require.NoError(t, err)
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 100 {
+		at := base.Add(time.Duration(i) * time.Hour)
+		engine.Process(Event{ID: uint64(i), CreatedAt: &at, UserName: fmt.Sprintf("user%d", i), IPAddr: "192.0.2.1"})
+	}
+
+	// Only the groups still within their window or expiry are kept
+	assert.Len(t, engine.rules[0].windows, 1)
+	assert.LessOrEqual(t, len(engine.rules[1].seen), 48)
+}
+
+func TestNewAlertEngineInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []AlertRule
+		err   string
+	}{
+		{name: "no rules", err: "no rules defined"},
+		{name: "no name", rules: []AlertRule{{}}, err: "rule 1: name is required"},
+		{name: "duplicate", rules: []AlertRule{{Name: "a"}, {Name: "a"}}, err: "rule a: duplicate name"},
+		{name: "unknown field", rules: []AlertRule{{Name: "a", Match: map[string]AlertRuleValue{"colour": {"red"}}}}, err: `rule a: unknown event field "colour"`},
+		{name: "no geoip", rules: []AlertRule{{Name: "a", Match: map[string]AlertRuleValue{"country": {"JP"}}}}, err: `rule a: field "country" requires a GeoIP database`},
+		{name: "bad expire", rules: []AlertRule{{Name: "a", NewValue: &AlertNewValue{Field: "ipaddr", Expire: "never"}}}, err: `rule a: invalid new_value expire "never"`},
+		{name: "bad window", rules: []AlertRule{{Name: "a", Threshold: &AlertThreshold{Count: 1, Window: "soon"}}}, err: `rule a: invalid threshold window "soon"`},
+		{name: "bad count", rules: []AlertRule{{Name: "a", Threshold: &AlertThreshold{Window: "5m"}}}, err: "rule a: threshold count must be positive"},
+		{name: "both", rules: []AlertRule{{Name: "a", Threshold: &AlertThreshold{Count: 1, Window: "5m"}, NewValue: &AlertNewValue{Field: "ipaddr"}}}, err: "rule a: threshold and new_value cannot be used together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAlertEngine(tt.rules, nil)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package onelogin
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/pepabo/onecli/utils"
 )
@@ -26,6 +28,32 @@ func (o *Onelogin) GetApps(query AppQuery) ([]App, error) {
 	}, DefaultPageSize)
 }
 
+// FindAppIDs returns the IDs of the apps named name, ignoring case. Several
+// apps can share a name, so all of them are returned.
+func (o *Onelogin) FindAppIDs(name string) ([]int32, error) {
+	return utils.Cached(o.cache, "app_ids:"+strings.ToLower(name), func() ([]int32, error) {
+		return o.findAppIDs(name)
+	})
+}
+
+func (o *Onelogin) findAppIDs(name string) ([]int32, error) {
+	apps, err := o.GetApps(AppQuery{Name: &name})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int32
+	for _, app := range apps {
+		if app.ID != nil && app.Name != nil && strings.EqualFold(*app.Name, name) {
+			ids = append(ids, *app.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("app %q not found", name)
+	}
+	return ids, nil
+}
+
 // GetAppsDetails retrieves apps with user details from Onelogin
 func (o *Onelogin) GetAppsDetails(query AppQuery) ([]AppDetails, error) {
 	apps, err := o.GetApps(query)
@@ -72,3 +100,52 @@ func (o *Onelogin) GetAppUsers(appID int) ([]User, error) {
 		return utils.ConvertToUsers(result.([]any))
 	}, DefaultPageSize)
 }
+
+// RoleQuery represents query parameters for roles
+type RoleQuery struct {
+	Limit string  `json:"limit,omitempty"`
+	Page  string  `json:"page,omitempty"`
+	ID    *string `json:"id,omitempty"`
+	AppID *string `json:"app_id,omitempty"`
+}
+
+// GetKeyValidators returns the validators for the query parameters
+func (q RoleQuery) GetKeyValidators() map[string]func(any) bool {
+	return map[string]func(any) bool{
+		"limit":  validateString,
+		"page":   validateString,
+		"id":     validateString,
+		"app_id": validateString,
+	}
+}
+
+// GetAppRoles retrieves the roles that grant access to a specific app
+func (o *Onelogin) GetAppRoles(appID int) ([]Role, error) {
+	id := strconv.Itoa(appID)
+	query := RoleQuery{
+		Limit: strconv.Itoa(DefaultPageSize),
+		AppID: &id,
+	}
+	return utils.Paginate(func(page int) ([]Role, error) {
+		query.Page = strconv.Itoa(page)
+		result, err := o.client.GetRoles(&query)
+		if err != nil {
+			return nil, err
+		}
+		return utils.ConvertToSlice[Role](result.([]any))
+	}, DefaultPageSize)
+}
+
+// GetApp retrieves a single app, including its SSO and parameter settings
+// that are omitted from GetApps
+func (o *Onelogin) GetApp(appID int) (App, error) {
+	result, err := o.client.GetAppByID(appID)
+	if err != nil {
+		return App{}, err
+	}
+	apps, err := utils.ConvertToApps([]any{result})
+	if err != nil {
+		return App{}, err
+	}
+	return apps[0], nil
+}
@@ -0,0 +1,134 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+)
+
+type AppParameter = models.Parameter
+
+// NamedAppParameter is an app parameter together with its key in
+// App.Parameters, flattened so it can be printed as a CSV row
+type NamedAppParameter struct {
+	Name                    string `json:"name"`
+	ID                      int    `json:"id,omitempty"`
+	Label                   string `json:"label,omitempty"`
+	UserAttributeMappings   any    `json:"user_attribute_mappings,omitempty"`
+	UserAttributeMacros     any    `json:"user_attribute_macros,omitempty"`
+	IncludeInSamlAssertion  bool   `json:"include_in_saml_assertion"`
+	ProvisionedEntitlements bool   `json:"provisioned_entitlements"`
+	SkipIfBlank             bool   `json:"skip_if_blank"`
+	Values                  any    `json:"values,omitempty"`
+	DefaultValues           any    `json:"default_values,omitempty"`
+}
+
+// AppParameters returns the parameters of an app sorted by name
+func AppParameters(app App) []NamedAppParameter {
+	params := []NamedAppParameter{}
+	if app.Parameters == nil {
+		return params
+	}
+	for name, p := range *app.Parameters {
+		params = append(params, NamedAppParameter{
+			Name:                    name,
+			ID:                      p.ID,
+			Label:                   p.Label,
+			UserAttributeMappings:   p.UserAttributeMappings,
+			UserAttributeMacros:     p.UserAttributeMacros,
+			IncludeInSamlAssertion:  p.IncludeInSamlAssertion,
+			ProvisionedEntitlements: p.ProvisionedEntitlements,
+			SkipIfBlank:             p.SkipIfBlank,
+			Values:                  p.Values,
+			DefaultValues:           p.DefaultValues,
+		})
+	}
+	slices.SortFunc(params, func(a, b NamedAppParameter) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return params
+}
+
+// SetAppParameter adds or replaces a parameter of an app through the app
+// update endpoint. Only the connector, name and parameters are sent so that
+// other settings of the app are left untouched.
+func (o *Onelogin) SetAppParameter(app App, name string, param AppParameter) error {
+	if app.ID == nil {
+		return fmt.Errorf("app has no ID")
+	}
+
+	params := map[string]appParameterPayload{}
+	if app.Parameters != nil {
+		for n, p := range *app.Parameters {
+			params[n] = newAppParameterPayload(p)
+		}
+	}
+	params[name] = newAppParameterPayload(param)
+
+	_, err := o.client.UpdateApp(int(*app.ID), appUpdatePayload{
+		ConnectorID: app.ConnectorID,
+		Name:        app.Name,
+		Parameters:  params,
+	})
+	return err
+}
+
+// appUpdatePayload is the body SetAppParameter sends to the app update
+// endpoint.
+type appUpdatePayload struct {
+	ConnectorID *int32                         `json:"connector_id"`
+	Name        *string                        `json:"name"`
+	Parameters  map[string]appParameterPayload `json:"parameters"`
+}
+
+// appParameterPayload mirrors models.Parameter for update requests.
+// models.Parameter tags its bool fields with omitempty, so a false value
+// would be dropped from the request and an option could never be turned off.
+type appParameterPayload struct {
+	ID                        int    `json:"id,omitempty"`
+	Label                     string `json:"label,omitempty"`
+	UserAttributeMappings     any    `json:"user_attribute_mappings,omitempty"`
+	UserAttributeMacros       any    `json:"user_attribute_macros,omitempty"`
+	AttributesTransformations any    `json:"attributes_transformations,omitempty"`
+	IncludeInSamlAssertion    bool   `json:"include_in_saml_assertion"`
+	ProvisionedEntitlements   bool   `json:"provisioned_entitlements"`
+	SkipIfBlank               bool   `json:"skip_if_blank"`
+	Values                    any    `json:"values,omitempty"`
+	DefaultValues             any    `json:"default_values"`
+}
+
+func newAppParameterPayload(p AppParameter) appParameterPayload {
+	return appParameterPayload{
+		ID:                        p.ID,
+		Label:                     p.Label,
+		UserAttributeMappings:     p.UserAttributeMappings,
+		UserAttributeMacros:       p.UserAttributeMacros,
+		AttributesTransformations: p.AttributesTransformations,
+		IncludeInSamlAssertion:    p.IncludeInSamlAssertion,
+		ProvisionedEntitlements:   p.ProvisionedEntitlements,
+		SkipIfBlank:               p.SkipIfBlank,
+		Values:                    p.Values,
+		DefaultValues:             p.DefaultValues,
+	}
+}
+
+// RemoveAppParameter removes a parameter from an app. Leaving a parameter
+// out of an update does not delete it, so this uses the dedicated parameter
+// delete endpoint.
+func (o *Onelogin) RemoveAppParameter(app App, name string) error {
+	if app.ID == nil {
+		return fmt.Errorf("app has no ID")
+	}
+	if app.Parameters == nil {
+		return fmt.Errorf("app %d has no parameter %q", *app.ID, name)
+	}
+	param, ok := (*app.Parameters)[name]
+	if !ok {
+		return fmt.Errorf("app %d has no parameter %q", *app.ID, name)
+	}
+
+	_, err := o.client.DeleteAppParameter(int(*app.ID), param.ID)
+	return err
+}
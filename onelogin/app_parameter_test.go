@@ -0,0 +1,105 @@
+package onelogin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testAppWithParameters() App {
+	id := int32(123)
+	connectorID := int32(110016)
+	name := "Test App"
+	params := map[string]AppParameter{
+		"email": {ID: 1, Label: "Email", UserAttributeMappings: "email", IncludeInSamlAssertion: true},
+		"title": {ID: 2, Label: "Title", UserAttributeMappings: "title"},
+	}
+	return App{ID: &id, ConnectorID: &connectorID, Name: &name, Parameters: &params}
+}
+
+func TestAppParameters(t *testing.T) {
+	params := AppParameters(testAppWithParameters())
+
+	assert.Equal(t, []NamedAppParameter{
+		{Name: "email", ID: 1, Label: "Email", UserAttributeMappings: "email", IncludeInSamlAssertion: true},
+		{Name: "title", ID: 2, Label: "Title", UserAttributeMappings: "title"},
+	}, params)
+
+	assert.Equal(t, []NamedAppParameter{}, AppParameters(App{}))
+}
+
+func TestSetAppParameter(t *testing.T) {
+	app := testAppWithParameters()
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	department := AppParameter{Label: "Department", UserAttributeMappings: "department", IncludeInSamlAssertion: true}
+	expectedParams := map[string]appParameterPayload{
+		"email":      newAppParameterPayload((*app.Parameters)["email"]),
+		"title":      newAppParameterPayload((*app.Parameters)["title"]),
+		"department": newAppParameterPayload(department),
+	}
+	mockClient.On("UpdateApp", 123, appUpdatePayload{
+		ConnectorID: app.ConnectorID,
+		Name:        app.Name,
+		Parameters:  expectedParams,
+	}).Return(map[string]any{"id": float64(123)}, nil)
+
+	err := o.SetAppParameter(app, "department", department)
+
+	assert.NoError(t, err)
+	// The app passed in must not be modified
+	assert.Len(t, *app.Parameters, 2)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSetAppParameterSendsFalse(t *testing.T) {
+	app := testAppWithParameters()
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	var body []byte
+	mockClient.On("UpdateApp", 123, mock.Anything).Run(func(args mock.Arguments) {
+		var err error
+		body, err = json.Marshal(args.Get(1))
+		require.NoError(t, err)
+	}).Return(map[string]any{"id": float64(123)}, nil)
+
+	// Turning off include_in_saml_assertion must reach the server; the SDK's
+	// models.Parameter would drop the false value
+	email := (*app.Parameters)["email"]
+	email.IncludeInSamlAssertion = false
+	require.NoError(t, o.SetAppParameter(app, "email", email))
+
+	var sent struct {
+		Parameters map[string]map[string]any `json:"parameters"`
+	}
+	require.NoError(t, json.Unmarshal(body, &sent))
+	assert.Contains(t, string(body), `"include_in_saml_assertion":false`)
+	assert.Equal(t, false, sent.Parameters["email"]["include_in_saml_assertion"])
+	assert.Equal(t, false, sent.Parameters["email"]["skip_if_blank"])
+	assert.Equal(t, false, sent.Parameters["email"]["provisioned_entitlements"])
+	mockClient.AssertExpectations(t)
+}
+
+func TestRemoveAppParameter(t *testing.T) {
+	app := testAppWithParameters()
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("DeleteAppParameter", 123, 2).Return(map[string]any{"status": "success"}, nil)
+
+	assert.NoError(t, o.RemoveAppParameter(app, "title"))
+	assert.Error(t, o.RemoveAppParameter(app, "missing"))
+	mockClient.AssertExpectations(t)
+}
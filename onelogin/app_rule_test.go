@@ -0,0 +1,197 @@
+package onelogin
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAppRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		appID         int
+		mockResponse  []any
+		mockError     error
+		expectedRules []AppRule
+		expectedError error
+	}{
+		{
+			name:  "successful app rules retrieval",
+			appID: 123,
+			mockResponse: []any{
+				map[string]any{
+					"id":       float64(10),
+					"name":     "Engineers",
+					"enabled":  true,
+					"match":    "all",
+					"position": float64(1),
+					"conditions": []any{
+						map[string]any{"source": "member_of", "operator": "ri", "value": "engineering"},
+					},
+					"actions": []any{
+						map[string]any{"action": "set_role", "value": []any{"42"}},
+					},
+				},
+			},
+			expectedRules: []AppRule{
+				{
+					ID:       10,
+					Name:     "Engineers",
+					Enabled:  true,
+					Match:    "all",
+					Position: 1,
+					Conditions: []AppRuleCondition{
+						{Source: "member_of", Operator: "ri", Value: "engineering"},
+					},
+					Actions: []AppRuleAction{
+						{Action: "set_role", Value: []string{"42"}},
+					},
+				},
+			},
+		},
+		{
+			name:          "successful app rules retrieval with empty result",
+			appID:         456,
+			mockResponse:  []any{},
+			expectedRules: []AppRule{},
+		},
+		{
+			name:          "error from client",
+			appID:         789,
+			mockError:     assert.AnError,
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(utils.MockClient)
+			o := &Onelogin{
+				client: mockClient,
+			}
+
+			expectedQuery := &AppRuleQuery{
+				Limit: strconv.Itoa(DefaultPageSize),
+				Page:  "1",
+			}
+			mockClient.On("GetAppRules", tt.appID, expectedQuery).Return(tt.mockResponse, tt.mockError)
+
+			rules, err := o.GetAppRules(tt.appID)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				assert.NoError(t, err)
+				if rules == nil {
+					rules = []AppRule{}
+				}
+				assert.Equal(t, tt.expectedRules, rules)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAppRule(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetAppRuleByID", 123, 10).Return(map[string]any{
+		"id":      float64(10),
+		"name":    "Engineers",
+		"enabled": false,
+		"match":   "any",
+	}, nil)
+
+	rule, err := o.GetAppRule(123, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, AppRule{ID: 10, Name: "Engineers", Match: "any"}, rule)
+	mockClient.AssertExpectations(t)
+}
+
+func TestCreateAppRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  any
+		mockError     error
+		expectedID    int
+		expectedError bool
+	}{
+		{
+			name:         "successful app rule creation",
+			mockResponse: map[string]any{"id": float64(99)},
+			expectedID:   99,
+		},
+		{
+			name:          "missing id in response",
+			mockResponse:  map[string]any{},
+			expectedError: true,
+		},
+		{
+			name:          "error from client",
+			mockError:     assert.AnError,
+			expectedError: true,
+		},
+	}
+
+	rule := AppRule{
+		ID:         5,
+		Name:       "Engineers",
+		Enabled:    true,
+		Match:      "all",
+		Conditions: []AppRuleCondition{{Source: "member_of", Operator: "ri", Value: "engineering"}},
+		Actions:    []AppRuleAction{{Action: "set_role", Value: []string{"42"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(utils.MockClient)
+			o := &Onelogin{
+				client: mockClient,
+			}
+
+			// The rule ID is assigned by OneLogin and must not be sent.
+			mockClient.On("CreateAppRule", 123, models.AppRule{
+				AppID:      123,
+				Name:       rule.Name,
+				Enabled:    rule.Enabled,
+				Match:      rule.Match,
+				Conditions: rule.Conditions,
+				Actions:    rule.Actions,
+			}).Return(tt.mockResponse, tt.mockError)
+
+			id, err := o.CreateAppRule(123, rule)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteAndSortAppRules(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("DeleteAppRule", 123, 10).Return(map[string]any{"status": "success"}, nil)
+	mockClient.On("SortAppRules", 123, []int{11, 12}).Return([]any{float64(11), float64(12)}, nil)
+
+	assert.NoError(t, o.DeleteAppRule(123, 10))
+	assert.NoError(t, o.SortAppRules(123, []int{11, 12}))
+	mockClient.AssertExpectations(t)
+}
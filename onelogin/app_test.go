@@ -465,3 +465,57 @@ func TestGetAppUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAppRoles(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	appID := "123"
+	expectedQuery := &RoleQuery{
+		Limit: strconv.Itoa(DefaultPageSize),
+		Page:  "1",
+		AppID: &appID,
+	}
+	mockClient.On("GetRoles", expectedQuery).Return([]any{
+		map[string]any{
+			"id":   float64(42),
+			"name": "Engineering",
+		},
+	}, nil)
+
+	roles, err := o.GetAppRoles(123)
+
+	assert.NoError(t, err)
+	id := int32(42)
+	name := "Engineering"
+	assert.Equal(t, []Role{{ID: &id, Name: &name}}, roles)
+	mockClient.AssertExpectations(t)
+}
+
+func TestFindAppIDs(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	name := "slack"
+	mockClient.On("GetApps", &models.AppQuery{
+		Limit: strconv.Itoa(DefaultPageSize),
+		Page:  "1",
+		Name:  &name,
+	}).Return([]any{
+		map[string]any{"id": float64(1), "name": "Slack"},
+		map[string]any{"id": float64(2), "name": "Slack (Sandbox)"},
+		map[string]any{"id": float64(3), "name": "slack"},
+	}, nil)
+
+	ids, err := o.FindAppIDs("slack")
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 3}, ids)
+
+	mockClient.On("GetApps", mock.Anything).Return([]any{}, nil)
+	_, err = o.FindAppIDs("missing")
+	assert.EqualError(t, err, `app "missing" not found`)
+}
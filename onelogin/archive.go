@@ -0,0 +1,480 @@
+package onelogin
+
+import (
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const archiveManifestFile = "manifest.json"
+
+// ArchiveRange is a period of time, [Since, Until]
+type ArchiveRange struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+// archiveManifest records the periods whose events are in the archive
+type archiveManifest struct {
+	Ranges []ArchiveRange `json:"ranges"`
+}
+
+// EventArchive is a directory of gzip-compressed NDJSON files, one per UTC
+// day (YYYY/MM/YYYY-MM-DD.ndjson.gz), with a manifest of the periods covered.
+//
+// Events are appended to the day files as separate gzip members, and a period
+// is added to the manifest only after its events are on disk. A run that dies
+// halfway may therefore leave events of a period that is not in the manifest
+// yet; they are fetched again by the next run and de-duplicated by event ID
+// when reading.
+type EventArchive struct {
+	dir      string
+	manifest archiveManifest
+}
+
+// OpenEventArchive opens the archive in dir, creating the directory if needed
+func OpenEventArchive(dir string) (*EventArchive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	a := &EventArchive{dir: dir}
+
+	b, err := os.ReadFile(filepath.Join(dir, archiveManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &a.manifest); err != nil {
+		return nil, fmt.Errorf("invalid archive manifest in %s: %v", dir, err)
+	}
+	return a, nil
+}
+
+// Ranges returns the periods covered by the archive, oldest first
+func (a *EventArchive) Ranges() []ArchiveRange {
+	return slices.Clone(a.manifest.Ranges)
+}
+
+// Gaps returns the parts of [since, until] that the archive does not cover,
+// oldest first
+func (a *EventArchive) Gaps(since, until time.Time) []ArchiveRange {
+	since, until = since.UTC(), until.UTC()
+	var gaps []ArchiveRange
+	start := since
+	for _, r := range a.manifest.Ranges {
+		if !r.Until.After(start) {
+			continue
+		}
+		if !r.Since.Before(until) {
+			break
+		}
+		if r.Since.After(start) {
+			gaps = append(gaps, ArchiveRange{Since: start, Until: r.Since})
+		}
+		start = r.Until
+	}
+	if start.Before(until) {
+		gaps = append(gaps, ArchiveRange{Since: start, Until: until})
+	}
+	return gaps
+}
+
+// Uncovered returns the parts of the query's period that the archive does
+// not cover, oldest first. An unset Since or Until is taken as the start or
+// end of the archived periods, so only explicit bounds and the gaps between
+// runs are reported. It returns nil for an empty archive.
+func (a *EventArchive) Uncovered(query EventsQuery) ([]ArchiveRange, error) {
+	since, until, err := query.timeRange()
+	if err != nil {
+		return nil, err
+	}
+	ranges := a.manifest.Ranges
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+	if since.IsZero() {
+		since = ranges[0].Since
+	}
+	if until.IsZero() {
+		until = ranges[len(ranges)-1].Until
+	}
+	return a.Gaps(since, until), nil
+}
+
+// Add writes the events of a period to the day files and then records the
+// period in the manifest
+func (a *EventArchive) Add(r ArchiveRange, events []Event) error {
+	if err := a.Append(events); err != nil {
+		return err
+	}
+	a.manifest.Ranges = mergeRanges(append(a.manifest.Ranges, ArchiveRange{Since: r.Since.UTC(), Until: r.Until.UTC()}))
+	return a.saveManifest()
+}
+
+// Append writes events to the day files without recording any period as
+// covered, for events received from elsewhere than the events API. Events
+// without a time are skipped.
+func (a *EventArchive) Append(events []Event) error {
+	days := map[time.Time][]Event{}
+	for _, e := range events {
+		if e.CreatedAt == nil {
+			continue
+		}
+		day := e.CreatedAt.UTC().Truncate(24 * time.Hour)
+		days[day] = append(days[day], e)
+	}
+	for day, events := range days {
+		if err := a.appendDay(day, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune deletes the day files before the UTC day of before and drops that
+// period from the manifest. It returns the number of files deleted.
+func (a *EventArchive) Prune(before time.Time) (int, error) {
+	cutoff := before.UTC().Truncate(24 * time.Hour)
+
+	files, err := a.dayFiles()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if !f.day.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			return removed, err
+		}
+		removed++
+		// Remove the month and year directories once they are empty
+		_ = os.Remove(filepath.Dir(f.path))
+		_ = os.Remove(filepath.Dir(filepath.Dir(f.path)))
+	}
+
+	ranges := []ArchiveRange{}
+	for _, r := range a.manifest.Ranges {
+		if !r.Until.After(cutoff) {
+			continue
+		}
+		r.Since = maxTime(r.Since, cutoff)
+		ranges = append(ranges, r)
+	}
+	a.manifest.Ranges = ranges
+	return removed, a.saveManifest()
+}
+
+// Events returns an iterator over the archived events matching the query,
+// newest first like the events API. Every filter of the query is applied
+// locally.
+func (a *EventArchive) Events(query EventsQuery) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		since, until, err := query.timeRange()
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+
+		files, err := a.dayFiles()
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		for _, f := range slices.Backward(files) {
+			if (!since.IsZero() && f.day.Add(24*time.Hour).Before(since)) || (!until.IsZero() && f.day.After(until)) {
+				continue
+			}
+			events, err := readArchiveFile(f.path)
+			if err != nil {
+				yield(Event{}, err)
+				return
+			}
+			for _, e := range events {
+				if query.matchesArchived(e) && !yield(e, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DefaultArchiveLag is how far behind the current time the archive stops by
+// default, as OneLogin may store events a while after their created_at
+const DefaultArchiveLag = time.Hour
+
+// ArchiveEvents fetches the events between since and until that the archive
+// does not cover yet, one UTC day at a time, and adds each day to the archive
+// as soon as it is fetched. progress, if not nil, is called after each day.
+// It returns the number of events archived. A period is never fetched again
+// once covered, so until should lag behind the current time enough for late
+// events to have arrived (see DefaultArchiveLag).
+func (o *Onelogin) ArchiveEvents(a *EventArchive, since, until time.Time, progress func(r ArchiveRange, events int)) (int, error) {
+	// The API's time filters have second precision
+	since, until = since.UTC().Truncate(time.Second), until.UTC().Truncate(time.Second)
+
+	total := 0
+	for _, gap := range a.Gaps(since, until) {
+		for _, window := range splitByDay(gap) {
+			s, u := window.Since.Format(time.RFC3339), window.Until.Format(time.RFC3339)
+			events, err := o.ListEvents(EventsQuery{Since: &s, Until: &u})
+			if err != nil {
+				return total, err
+			}
+			if err := a.Add(window, events); err != nil {
+				return total, fmt.Errorf("error writing archive: %v", err)
+			}
+			total += len(events)
+			if progress != nil {
+				progress(window, len(events))
+			}
+		}
+	}
+	return total, nil
+}
+
+// splitByDay splits a period at UTC midnights
+func splitByDay(r ArchiveRange) []ArchiveRange {
+	var windows []ArchiveRange
+	for start := r.Since; start.Before(r.Until); {
+		end := minTime(start.Truncate(24*time.Hour).Add(24*time.Hour), r.Until)
+		windows = append(windows, ArchiveRange{Since: start, Until: end})
+		start = end
+	}
+	return windows
+}
+
+// mergeRanges sorts the ranges and merges the ones that overlap or touch
+func mergeRanges(ranges []ArchiveRange) []ArchiveRange {
+	slices.SortFunc(ranges, func(a, b ArchiveRange) int {
+		return a.Since.Compare(b.Since)
+	})
+	merged := []ArchiveRange{}
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && !r.Since.After(merged[n-1].Until) {
+			merged[n-1].Until = maxTime(merged[n-1].Until, r.Until)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func (a *EventArchive) dayPath(day time.Time) string {
+	return filepath.Join(a.dir, day.Format("2006"), day.Format("01"), day.Format(time.DateOnly)+".ndjson.gz")
+}
+
+// appendDay appends the events to the file of a day as a new gzip member
+func (a *EventArchive) appendDay(day time.Time, events []Event) error {
+	path := a.dayPath(day)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	encoder := json.NewEncoder(zw)
+	encoder.SetEscapeHTML(false)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type archiveFile struct {
+	day  time.Time
+	path string
+}
+
+// dayFiles returns the day files of the archive, oldest first
+func (a *EventArchive) dayFiles() ([]archiveFile, error) {
+	paths, err := filepath.Glob(filepath.Join(a.dir, "[0-9][0-9][0-9][0-9]", "[0-9][0-9]", "*.ndjson.gz"))
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	for _, path := range paths {
+		day, err := time.Parse(time.DateOnly, strings.TrimSuffix(filepath.Base(path), ".ndjson.gz"))
+		if err != nil {
+			continue
+		}
+		files = append(files, archiveFile{day: day, path: path})
+	}
+	slices.SortFunc(files, func(a, b archiveFile) int {
+		return a.day.Compare(b.day)
+	})
+	return files, nil
+}
+
+// readArchiveFile reads the events of a day file without duplicate IDs,
+// newest first
+func readArchiveFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	defer zr.Close()
+
+	seen := map[uint64]bool{}
+	var events []Event
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", path, err)
+		}
+		// Events without an ID cannot be told apart and are all kept
+		if e.ID != 0 && seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		events = append(events, e)
+	}
+	// A member cut short by a crash is dropped with the events after it
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	slices.SortFunc(events, func(a, b Event) int {
+		return cmp.Or(compareTimes(b.CreatedAt, a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	return events, nil
+}
+
+// timeRange parses the query's Since and Until. Unset times are zero.
+func (q EventsQuery) timeRange() (since, until time.Time, err error) {
+	if q.Since != nil && *q.Since != "" {
+		if since, err = time.Parse(time.RFC3339, *q.Since); err != nil {
+			return since, until, fmt.Errorf("invalid since: %v", err)
+		}
+	}
+	if q.Until != nil && *q.Until != "" {
+		if until, err = time.Parse(time.RFC3339, *q.Until); err != nil {
+			return since, until, fmt.Errorf("invalid until: %v", err)
+		}
+	}
+	return since, until, nil
+}
+
+// matchesArchived applies the filters the events API would apply, and the
+// client-side ones, to an archived event
+func (q EventsQuery) matchesArchived(e Event) bool {
+	matchInt := func(filter *string, value int64) bool {
+		if filter == nil || *filter == "" {
+			return true
+		}
+		for s := range strings.SplitSeq(*filter, ",") {
+			if n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil && n == value {
+				return true
+			}
+		}
+		return false
+	}
+	if !matchInt(q.DirectoryID, int64(e.DirectoryID)) ||
+		!matchInt(q.EventTypeID, int64(e.EventTypeID)) ||
+		!matchInt(q.Resolution, int64(e.Resolution)) ||
+		!matchInt(q.UserID, int64(e.UserID)) {
+		return false
+	}
+	if q.ID != nil && *q.ID != "" && *q.ID != strconv.FormatUint(e.ID, 10) {
+		return false
+	}
+	if q.ClientID != nil && *q.ClientID != "" && *q.ClientID != e.ClientID {
+		return false
+	}
+
+	since, until, _ := q.timeRange()
+	if q.CreatedAt != nil && *q.CreatedAt != "" {
+		createdAt, err := time.Parse(time.RFC3339, *q.CreatedAt)
+		if err != nil || e.CreatedAt == nil || !e.CreatedAt.Equal(createdAt) {
+			return false
+		}
+	}
+	if e.CreatedAt != nil {
+		if (!since.IsZero() && e.CreatedAt.Before(since)) || (!until.IsZero() && e.CreatedAt.After(until)) {
+			return false
+		}
+	}
+	return q.matches(e)
+}
+
+// saveManifest writes the manifest atomically
+func (a *EventArchive) saveManifest() error {
+	b, err := json.MarshalIndent(a.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(a.dir, archiveManifestFile)
+
+	tmp, err := os.CreateTemp(a.dir, archiveManifestFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,297 @@
+package onelogin
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func archiveTime(day, hour int) time.Time {
+	return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC)
+}
+
+func archiveEvent(id uint64, at time.Time, eventTypeID int32, userID int32) Event {
+	return Event{ID: id, CreatedAt: &at, EventTypeID: eventTypeID, UserID: userID}
+}
+
+func eventIDs(events []Event) []uint64 {
+	ids := []uint64{}
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestEventArchiveGaps(t *testing.T) {
+	a := &EventArchive{manifest: archiveManifest{Ranges: []ArchiveRange{
+		{Since: archiveTime(2, 0), Until: archiveTime(3, 0)},
+		{Since: archiveTime(5, 0), Until: archiveTime(6, 0)},
+	}}}
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		expected     []ArchiveRange
+	}{
+		{
+			name:  "around and between ranges",
+			since: archiveTime(1, 0), until: archiveTime(7, 0),
+			expected: []ArchiveRange{
+				{Since: archiveTime(1, 0), Until: archiveTime(2, 0)},
+				{Since: archiveTime(3, 0), Until: archiveTime(5, 0)},
+				{Since: archiveTime(6, 0), Until: archiveTime(7, 0)},
+			},
+		},
+		{name: "inside a range", since: archiveTime(2, 3), until: archiveTime(2, 9), expected: nil},
+		{
+			name:  "starting inside a range",
+			since: archiveTime(5, 12), until: archiveTime(6, 12),
+			expected: []ArchiveRange{{Since: archiveTime(6, 0), Until: archiveTime(6, 12)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, a.Gaps(tt.since, tt.until))
+		})
+	}
+}
+
+func TestEventArchiveAddAndRead(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenEventArchive(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Add(ArchiveRange{Since: archiveTime(1, 0), Until: archiveTime(2, 0)}, []Event{
+		archiveEvent(2, archiveTime(1, 12), 5, 100),
+		archiveEvent(1, archiveTime(1, 6), 6, 200),
+	}))
+	// The boundary second is fetched by both windows
+	require.NoError(t, a.Add(ArchiveRange{Since: archiveTime(2, 0), Until: archiveTime(3, 0)}, []Event{
+		archiveEvent(4, archiveTime(2, 9), 5, 200),
+		archiveEvent(3, archiveTime(2, 0), 5, 100),
+		archiveEvent(2, archiveTime(1, 12), 5, 100),
+	}))
+
+	assert.FileExists(t, filepath.Join(dir, "2026", "10", "2026-10-01.ndjson.gz"))
+	assert.FileExists(t, filepath.Join(dir, "2026", "10", "2026-10-02.ndjson.gz"))
+
+	// The manifest survives reopening, with adjacent ranges merged
+	a, err = OpenEventArchive(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []ArchiveRange{{Since: archiveTime(1, 0), Until: archiveTime(3, 0)}}, a.Ranges())
+
+	collect := func(query EventsQuery) []uint64 {
+		var events []Event
+		for e, err := range a.Events(query) {
+			require.NoError(t, err)
+			events = append(events, e)
+		}
+		return eventIDs(events)
+	}
+	str := func(s string) *string { return &s }
+
+	assert.Equal(t, []uint64{4, 3, 2, 1}, collect(EventsQuery{}))
+	assert.Equal(t, []uint64{4, 3, 2}, collect(EventsQuery{EventTypeID: str("5")}))
+	assert.Equal(t, []uint64{4, 1}, collect(EventsQuery{UserID: str("200")}))
+	assert.Equal(t, []uint64{3, 2}, collect(EventsQuery{
+		Since: str("2026-10-01T07:00:00Z"),
+		Until: str("2026-10-02T00:00:00Z"),
+	}))
+	assert.Equal(t, []uint64{1}, collect(EventsQuery{ID: str("1")}))
+}
+
+func TestEventArchiveAppend(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenEventArchive(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Append([]Event{
+		archiveEvent(1, archiveTime(1, 6), 5, 100),
+		archiveEvent(0, archiveTime(1, 7), 5, 100),
+		archiveEvent(0, archiveTime(1, 8), 5, 200),
+		{ID: 3},
+	}))
+	require.NoError(t, a.Append([]Event{archiveEvent(1, archiveTime(1, 6), 5, 100)}))
+
+	// Pushed events do not mark any period as covered
+	a, err = OpenEventArchive(dir)
+	require.NoError(t, err)
+	assert.Empty(t, a.Ranges())
+
+	var events []Event
+	for e, err := range a.Events(EventsQuery{}) {
+		require.NoError(t, err)
+		events = append(events, e)
+	}
+	assert.Equal(t, []uint64{0, 0, 1}, eventIDs(events))
+}
+
+func TestEventArchiveUncovered(t *testing.T) {
+	a := &EventArchive{}
+	uncovered, err := a.Uncovered(EventsQuery{})
+	require.NoError(t, err)
+	assert.Nil(t, uncovered)
+
+	a.manifest.Ranges = []ArchiveRange{
+		{Since: archiveTime(2, 0), Until: archiveTime(3, 0)},
+		{Since: archiveTime(5, 0), Until: archiveTime(6, 0)},
+	}
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name     string
+		query    EventsQuery
+		expected []ArchiveRange
+	}{
+		{
+			name:     "unbounded reports the gap between runs",
+			expected: []ArchiveRange{{Since: archiveTime(3, 0), Until: archiveTime(5, 0)}},
+		},
+		{
+			name:     "inside a range",
+			query:    EventsQuery{Since: str("2026-10-02T01:00:00Z"), Until: str("2026-10-02T05:00:00Z")},
+			expected: nil,
+		},
+		{
+			name:  "explicit bounds beyond the archive",
+			query: EventsQuery{Since: str("2026-10-01T00:00:00Z"), Until: str("2026-10-02T12:00:00Z")},
+			expected: []ArchiveRange{
+				{Since: archiveTime(1, 0), Until: archiveTime(2, 0)},
+			},
+		},
+		{
+			name:  "after the last run",
+			query: EventsQuery{Since: str("2026-10-05T12:00:00Z"), Until: str("2026-10-07T00:00:00Z")},
+			expected: []ArchiveRange{
+				{Since: archiveTime(6, 0), Until: archiveTime(7, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uncovered, err := a.Uncovered(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, uncovered)
+		})
+	}
+}
+
+func TestEventArchiveTruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenEventArchive(dir)
+	require.NoError(t, err)
+	require.NoError(t, a.Add(ArchiveRange{Since: archiveTime(1, 0), Until: archiveTime(2, 0)}, []Event{
+		archiveEvent(1, archiveTime(1, 6), 5, 100),
+	}))
+
+	// Simulate a crash while a second member was being written
+	path := filepath.Join(dir, "2026", "10", "2026-10-01.ndjson.gz")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	zw := gzip.NewWriter(f)
+	_, err = zw.Write([]byte(`{"id":2,"created_at":"2026-10-01T07:00:00Z"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Flush())
+	require.NoError(t, f.Close())
+
+	var ids []uint64
+	for e, err := range a.Events(EventsQuery{}) {
+		require.NoError(t, err)
+		ids = append(ids, e.ID)
+	}
+	assert.Contains(t, ids, uint64(1))
+}
+
+func TestEventArchivePrune(t *testing.T) {
+	dir := t.TempDir()
+	a, err := OpenEventArchive(dir)
+	require.NoError(t, err)
+	require.NoError(t, a.Add(ArchiveRange{Since: archiveTime(1, 0), Until: archiveTime(3, 12)}, []Event{
+		archiveEvent(1, archiveTime(1, 6), 5, 100),
+		archiveEvent(2, archiveTime(2, 6), 5, 100),
+		archiveEvent(3, archiveTime(3, 6), 5, 100),
+	}))
+
+	removed, err := a.Prune(archiveTime(2, 18))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoFileExists(t, filepath.Join(dir, "2026", "10", "2026-10-01.ndjson.gz"))
+	assert.Equal(t, []ArchiveRange{{Since: archiveTime(2, 0), Until: archiveTime(3, 12)}}, a.Ranges())
+
+	var ids []uint64
+	for e, err := range a.Events(EventsQuery{}) {
+		require.NoError(t, err)
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []uint64{3, 2}, ids)
+}
+
+func TestArchiveEvents(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{client: mockClient}
+
+	a, err := OpenEventArchive(t.TempDir())
+	require.NoError(t, err)
+	// The first day is already archived
+	require.NoError(t, a.Add(ArchiveRange{Since: archiveTime(1, 0), Until: archiveTime(2, 0)}, nil))
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{"data": []any{
+		map[string]any{"id": float64(5), "name": "USER_LOGGED_INTO_ONELOGIN"},
+	}}, nil)
+	window := func(since, until string, events ...map[string]any) {
+		data := []any{}
+		for _, e := range events {
+			data = append(data, e)
+		}
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit: strconv.Itoa(DefaultPageSize),
+			Since: &since,
+			Until: &until,
+		}).Return(map[string]any{"data": data}, nil).Once()
+	}
+	window("2026-10-02T00:00:00Z", "2026-10-03T00:00:00Z",
+		map[string]any{"id": float64(2), "created_at": "2026-10-02T10:00:00Z", "event_type_id": float64(5)})
+	window("2026-10-03T00:00:00Z", "2026-10-03T12:30:00Z",
+		map[string]any{"id": float64(3), "created_at": "2026-10-03T11:00:00Z", "event_type_id": float64(5)})
+
+	var progress []ArchiveRange
+	n, err := o.ArchiveEvents(a, archiveTime(1, 0), archiveTime(3, 12).Add(30*time.Minute+500*time.Millisecond), func(r ArchiveRange, _ int) {
+		progress = append(progress, r)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, progress, 2)
+	assert.Equal(t, []ArchiveRange{{Since: archiveTime(1, 0), Until: archiveTime(3, 12).Add(30 * time.Minute)}}, a.Ranges())
+	mockClient.AssertNumberOfCalls(t, "ListEvents", 2)
+
+	var events []Event
+	for e, err := range a.Events(EventsQuery{}) {
+		require.NoError(t, err)
+		events = append(events, e)
+	}
+	assert.Equal(t, []uint64{3, 2}, eventIDs(events))
+	assert.Equal(t, "USER_LOGGED_INTO_ONELOGIN", events[0].EventType)
+
+	t.Run("nothing to fetch", func(t *testing.T) {
+		n, err := o.ArchiveEvents(a, archiveTime(1, 0), archiveTime(3, 0), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		mockClient.AssertNumberOfCalls(t, "ListEvents", 2)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		mockClient.On("ListEvents", mock.Anything).Return(nil, assert.AnError).Once()
+		_, err := o.ArchiveEvents(a, archiveTime(3, 0), archiveTime(4, 0), nil)
+		assert.Error(t, err)
+		// The failed day is not recorded as covered
+		assert.Equal(t, archiveTime(3, 12).Add(30*time.Minute), a.Ranges()[0].Until)
+	})
+}
@@ -0,0 +1,162 @@
+package onelogin
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultBroadcastMaxBodyBytes is the largest request body the broadcast
+// receiver accepts by default
+const DefaultBroadcastMaxBodyBytes = 10 << 20
+
+// BroadcastOptions configures the receiver of Event Broadcaster webhooks
+type BroadcastOptions struct {
+	// Token is expected as "Authorization: Bearer <token>" when set
+	Token string
+	// Username and Password are expected as HTTP basic auth when Username is
+	// set. Both use the Authorization header, so set either Token or Username.
+	Username string
+	Password string
+	// EventTypeNames fills in the name of each event by its type ID
+	EventTypeNames map[int32]string
+	// MaxBodyBytes limits the request body size, DefaultBroadcastMaxBodyBytes if zero
+	MaxBodyBytes int64
+}
+
+// broadcastEvent is an event as sent by the Event Broadcaster, which may carry
+// its time as event_timestamp instead of created_at
+type broadcastEvent struct {
+	Event
+	EventTimestamp string `json:"event_timestamp,omitempty"`
+}
+
+// broadcastTimeLayouts are the formats event_timestamp is parsed with
+var broadcastTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+}
+
+func (b broadcastEvent) event() Event {
+	e := b.Event
+	if e.CreatedAt == nil && b.EventTimestamp != "" {
+		for _, layout := range broadcastTimeLayouts {
+			if t, err := time.Parse(layout, b.EventTimestamp); err == nil {
+				t = t.UTC()
+				e.CreatedAt = &t
+				break
+			}
+		}
+	}
+	return e
+}
+
+// DecodeBroadcastEvents decodes the body of an Event Broadcaster request,
+// which is a JSON array of events, a single event, or one event per line
+func DecodeBroadcastEvents(body []byte) ([]Event, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("empty body")
+	}
+
+	var raw []broadcastEvent
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %v", err)
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		for {
+			var b broadcastEvent
+			err := decoder.Decode(&b)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON event %d: %v", len(raw)+1, err)
+			}
+			raw = append(raw, b)
+		}
+	}
+
+	events := make([]Event, len(raw))
+	for i, b := range raw {
+		events[i] = b.event()
+	}
+	return events, nil
+}
+
+// NewBroadcastHandler returns an HTTP handler that receives Event Broadcaster
+// webhooks. It authenticates each request, decodes the events, fills in their
+// type names and passes them to emit. A request is acknowledged only after
+// emit succeeds, so the broadcaster retries events that were not handled.
+func NewBroadcastHandler(opts BroadcastOptions, emit func([]Event) error) http.Handler {
+	maxBody := opts.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = DefaultBroadcastMaxBodyBytes
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !opts.authenticate(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="onecli"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "error reading body", http.StatusBadRequest)
+			return
+		}
+		events, err := DecodeBroadcastEvents(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for i := range events {
+			if events[i].EventType == "" {
+				events[i].EventType = opts.EventTypeNames[events[i].EventTypeID]
+			}
+		}
+		if err := emit(events); err != nil {
+			http.Error(w, "error handling events", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// authenticate checks the bearer token and basic auth credentials that are
+// configured, comparing them in constant time
+func (opts BroadcastOptions) authenticate(r *http.Request) bool {
+	equal := func(a, b string) bool {
+		return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+	}
+	if opts.Token != "" && !equal(r.Header.Get("Authorization"), "Bearer "+opts.Token) {
+		return false
+	}
+	if opts.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || !equal(username, opts.Username) || !equal(password, opts.Password) {
+			return false
+		}
+	}
+	return true
+}
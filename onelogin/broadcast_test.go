@@ -0,0 +1,172 @@
+package onelogin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBroadcastEvents(t *testing.T) {
+	at := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected []uint64
+		wantErr  bool
+	}{
+		{name: "array", body: `[{"id":1},{"id":2}]`, expected: []uint64{1, 2}},
+		{name: "single object", body: ` {"id":1} `, expected: []uint64{1}},
+		{name: "one event per line", body: "{\"id\":1}\n{\"id\":2}\n", expected: []uint64{1, 2}},
+		{name: "empty", body: " \n", wantErr: true},
+		{name: "invalid array", body: `[{"id":1}`, wantErr: true},
+		{name: "invalid line", body: "{\"id\":1}\nnot json\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := DecodeBroadcastEvents([]byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, eventIDs(events))
+		})
+	}
+
+	t.Run("event timestamp", func(t *testing.T) {
+		events, err := DecodeBroadcastEvents([]byte(`[
+			{"id":1,"event_timestamp":"2026-10-01 12:30:00 UTC"},
+			{"id":2,"event_timestamp":"2026-10-01T21:30:00+09:00"},
+			{"id":3,"created_at":"2026-10-01T12:30:00Z","event_timestamp":"garbage"},
+			{"id":4,"event_timestamp":"garbage"}
+		]`))
+		require.NoError(t, err)
+		require.Len(t, events, 4)
+		for _, e := range events[:3] {
+			require.NotNil(t, e.CreatedAt, "event %d", e.ID)
+			assert.True(t, at.Equal(*e.CreatedAt), "event %d", e.ID)
+		}
+		assert.Nil(t, events[3].CreatedAt)
+	})
+}
+
+func TestBroadcastHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       BroadcastOptions
+		method     string
+		body       string
+		setup      func(r *http.Request)
+		emitErr    error
+		wantStatus int
+		wantTypes  []string
+	}{
+		{
+			name:       "bearer token",
+			opts:       BroadcastOptions{Token: "s3cret", EventTypeNames: map[int32]string{5: "USER_LOGGED_INTO_ONELOGIN"}},
+			body:       `[{"id":1,"event_type_id":5},{"id":2,"event_type_id":6},{"id":3,"event_type_id":5,"event_type":"KEPT"}]`,
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") },
+			wantStatus: http.StatusNoContent,
+			wantTypes:  []string{"USER_LOGGED_INTO_ONELOGIN", "", "KEPT"},
+		},
+		{
+			name:       "wrong bearer token",
+			opts:       BroadcastOptions{Token: "s3cret"},
+			body:       `{"id":1}`,
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing credentials",
+			opts:       BroadcastOptions{Token: "s3cret"},
+			body:       `{"id":1}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic auth",
+			opts:       BroadcastOptions{Username: "onelogin", Password: "pw"},
+			body:       `{"id":1}`,
+			setup:      func(r *http.Request) { r.SetBasicAuth("onelogin", "pw") },
+			wantStatus: http.StatusNoContent,
+			wantTypes:  []string{""},
+		},
+		{
+			name:       "wrong basic auth password",
+			opts:       BroadcastOptions{Username: "onelogin", Password: "pw"},
+			body:       `{"id":1}`,
+			setup:      func(r *http.Request) { r.SetBasicAuth("onelogin", "nope") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no authentication configured",
+			body:       `{"id":1}`,
+			wantStatus: http.StatusNoContent,
+			wantTypes:  []string{""},
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "body too large",
+			opts:       BroadcastOptions{MaxBodyBytes: 8},
+			body:       `[{"id":1},{"id":2}]`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "invalid body",
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "emit fails",
+			body:       `{"id":1}`,
+			emitErr:    errors.New("collector down"),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var emitted []Event
+			handler := NewBroadcastHandler(tt.opts, func(events []Event) error {
+				emitted = append(emitted, events...)
+				return tt.emitErr
+			})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, "/", strings.NewReader(tt.body))
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+			if tt.wantTypes == nil {
+				if tt.emitErr == nil {
+					assert.Empty(t, emitted)
+				}
+				return
+			}
+			types := make([]string, len(emitted))
+			for i, e := range emitted {
+				types[i] = e.EventType
+			}
+			assert.Equal(t, tt.wantTypes, types)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package onelogin
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AppCertificate represents the SAML signing certificate of an app
+type AppCertificate struct {
+	AppID           int32     `json:"app_id"`
+	AppName         string    `json:"app_name"`
+	CertificateID   int       `json:"certificate_id,omitempty"`
+	CertificateName string    `json:"certificate_name,omitempty"`
+	Subject         string    `json:"subject"`
+	NotAfter        time.Time `json:"not_after"`
+	// Error is set instead of the certificate fields when the app or its
+	// certificate could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// GetAppCertificates retrieves the SAML signing certificate of every app
+// matching the query, sorted by expiry (soonest first). Apps without a
+// certificate, such as OIDC or form-based apps, are skipped. Apps whose
+// certificate cannot be read are returned last with Error set, so that one
+// broken app does not hide the others.
+func (o *Onelogin) GetAppCertificates(query AppQuery) ([]AppCertificate, error) {
+	apps, err := o.GetApps(query)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := []AppCertificate{}
+	for _, app := range apps {
+		if app.ID == nil {
+			continue
+		}
+
+		failed := AppCertificate{AppID: *app.ID}
+		if app.Name != nil {
+			failed.AppName = *app.Name
+		}
+
+		detail, err := o.GetApp(int(*app.ID))
+		if err != nil {
+			failed.Error = fmt.Sprintf("error getting app: %v", err)
+			certs = append(certs, failed)
+			continue
+		}
+
+		cert, err := appSAMLCertificate(detail)
+		if err != nil {
+			failed.Error = fmt.Sprintf("error reading certificate: %v", err)
+			certs = append(certs, failed)
+			continue
+		}
+		if cert == nil {
+			continue
+		}
+
+		certs = append(certs, *cert)
+	}
+
+	slices.SortStableFunc(certs, func(a, b AppCertificate) int {
+		if (a.Error != "") != (b.Error != "") {
+			if a.Error != "" {
+				return 1
+			}
+			return -1
+		}
+		return a.NotAfter.Compare(b.NotAfter)
+	})
+
+	return certs, nil
+}
+
+// appSAMLCertificate extracts and parses sso.certificate of an app.
+// It returns nil if the app has no certificate.
+func appSAMLCertificate(app App) (*AppCertificate, error) {
+	if app.SSO == nil {
+		return nil, nil
+	}
+
+	// App.SSO is untyped because its shape depends on the connector
+	b, err := json.Marshal(app.SSO)
+	if err != nil {
+		return nil, err
+	}
+	var sso struct {
+		Certificate *struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"certificate"`
+	}
+	if err := json.Unmarshal(b, &sso); err != nil {
+		return nil, err
+	}
+	if sso.Certificate == nil || sso.Certificate.Value == "" {
+		return nil, nil
+	}
+
+	x509Cert, err := parseCertificate(sso.Certificate.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &AppCertificate{
+		CertificateID:   sso.Certificate.ID,
+		CertificateName: sso.Certificate.Name,
+		Subject:         x509Cert.Subject.String(),
+		NotAfter:        x509Cert.NotAfter,
+	}
+	if app.ID != nil {
+		cert.AppID = *app.ID
+	}
+	if app.Name != nil {
+		cert.AppName = *app.Name
+	}
+	return cert, nil
+}
+
+// parseCertificate parses a PEM encoded certificate, or a bare base64 DER
+// certificate as some connectors return it without the PEM armor
+func parseCertificate(value string) (*x509.Certificate, error) {
+	if block, _ := pem.Decode([]byte(value)); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return nil, fmt.Errorf("certificate is neither PEM nor base64 DER")
+	}
+	return x509.ParseCertificate(der)
+}
@@ -0,0 +1,106 @@
+package onelogin
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(t *testing.T, cn string, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestGetAppCertificates(t *testing.T) {
+	later := time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)
+	sooner := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	laterPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newTestCertificate(t, "later", later)}))
+	soonerDER := base64.StdEncoding.EncodeToString(newTestCertificate(t, "sooner", sooner))
+
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetApps", &models.AppQuery{
+		Limit: strconv.Itoa(DefaultPageSize),
+		Page:  "1",
+	}).Return([]any{
+		map[string]any{"id": float64(1), "name": "Later App"},
+		map[string]any{"id": float64(2), "name": "OIDC App"},
+		map[string]any{"id": float64(3), "name": "Sooner App"},
+		map[string]any{"id": float64(4), "name": "Broken App"},
+		map[string]any{"id": float64(5), "name": "Missing App"},
+	}, nil)
+	mockClient.On("GetAppByID", 1).Return(map[string]any{
+		"id":   float64(1),
+		"name": "Later App",
+		"sso": map[string]any{
+			"certificate": map[string]any{"id": float64(11), "name": "Later Cert", "value": laterPEM},
+		},
+	}, nil)
+	mockClient.On("GetAppByID", 2).Return(map[string]any{
+		"id":   float64(2),
+		"name": "OIDC App",
+		"sso":  map[string]any{"client_id": "abc"},
+	}, nil)
+	mockClient.On("GetAppByID", 3).Return(map[string]any{
+		"id":   float64(3),
+		"name": "Sooner App",
+		"sso": map[string]any{
+			"certificate": map[string]any{"id": float64(33), "name": "Sooner Cert", "value": soonerDER},
+		},
+	}, nil)
+
+	mockClient.On("GetAppByID", 4).Return(map[string]any{
+		"id":   float64(4),
+		"name": "Broken App",
+		"sso": map[string]any{
+			"certificate": map[string]any{"id": float64(44), "name": "Broken Cert", "value": "not a certificate"},
+		},
+	}, nil)
+	mockClient.On("GetAppByID", 5).Return(nil, errors.New("not found"))
+
+	certs, err := o.GetAppCertificates(AppQuery{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []AppCertificate{
+		{AppID: 3, AppName: "Sooner App", CertificateID: 33, CertificateName: "Sooner Cert", Subject: "CN=sooner", NotAfter: sooner},
+		{AppID: 1, AppName: "Later App", CertificateID: 11, CertificateName: "Later Cert", Subject: "CN=later", NotAfter: later},
+		{AppID: 4, AppName: "Broken App", Error: "error reading certificate: certificate is neither PEM nor base64 DER"},
+		{AppID: 5, AppName: "Missing App", Error: "error getting app: not found"},
+	}, certs)
+	mockClient.AssertExpectations(t)
+}
+
+func TestParseCertificateInvalid(t *testing.T) {
+	_, err := parseCertificate("not a certificate")
+	assert.Error(t, err)
+}
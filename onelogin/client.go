@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+	"github.com/pepabo/onecli/utils"
 )
 
 const (
@@ -21,6 +22,8 @@ type (
 	AppQuery = models.AppQuery
 )
 
+type Role = models.Role
+
 type Client interface {
 	GetUsers(query models.Queryable) (any, error)
 	UpdateUser(userID int, user models.User) (any, error)
@@ -28,13 +31,29 @@ type Client interface {
 	UpdatePasswordInsecure(userID int, requestBody any) (any, error)
 	SendInviteLink(invite models.Invite) (any, error)
 	GetApps(query models.Queryable) (any, error)
+	GetAppByID(appID int) (any, error)
+	UpdateApp(appID int, requestBody any) (any, error)
+	DeleteAppParameter(appID, parameterID int) (any, error)
 	GetAppUsers(appID int, query models.Queryable) (any, error)
+	GetAppRules(appID int, query models.Queryable) (any, error)
+	GetAppRuleByID(appID, ruleID int) (any, error)
+	CreateAppRule(appID int, rule models.AppRule) (any, error)
+	DeleteAppRule(appID, ruleID int) (any, error)
+	SortAppRules(appID int, ruleIDs []int) (any, error)
+	GetRoles(query models.Queryable) (any, error)
+	GetConnectors(query models.Queryable) (any, error)
 	ListEvents(query models.Queryable) (any, error)
+	GetEventByID(eventID int) (any, error)
 	GetEventTypes(query models.Queryable) (any, error)
 }
 
 type Onelogin struct {
 	client Client
+	// retry controls how rate-limited and failed event requests are retried
+	retry utils.RetryOptions
+	// cache keeps reference data such as event types between runs; nil
+	// disables it
+	cache *utils.Cache
 
 	eventTypesCache     []EventType
 	eventTypesCacheErr  error
@@ -48,5 +67,11 @@ func New() (*Onelogin, error) {
 		return nil, err
 	}
 
-	return &Onelogin{client: client}, nil
+	return &Onelogin{client: client, retry: utils.DefaultRetryOptions}, nil
+}
+
+// SetCache enables the on-disk cache for event types and name lookups. The
+// cache should be specific to the OneLogin subdomain.
+func (o *Onelogin) SetCache(cache *utils.Cache) {
+	o.cache = cache
 }
@@ -0,0 +1,65 @@
+package onelogin
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pepabo/onecli/utils"
+)
+
+// Connector represents an entry of the OneLogin connector catalogue.
+// Its ID is the connector_id used when creating an app.
+type Connector struct {
+	ID                  int32  `json:"id"`
+	Name                string `json:"name"`
+	AuthMethod          int32  `json:"auth_method"`
+	AllowsNewParameters bool   `json:"allows_new_parameters"`
+	IconURL             string `json:"icon_url,omitempty"`
+}
+
+// ConnectorQuery represents query parameters for connectors
+type ConnectorQuery struct {
+	Limit string `json:"limit,omitempty"`
+	Page  string `json:"page,omitempty"`
+}
+
+// GetKeyValidators returns the validators for the query parameters
+func (q ConnectorQuery) GetKeyValidators() map[string]func(any) bool {
+	return map[string]func(any) bool{
+		"limit": validateString,
+		"page":  validateString,
+	}
+}
+
+// GetConnectors retrieves connectors whose name contains name (case-insensitive).
+// The substring match is done client-side after paging through the whole
+// catalogue; an empty name returns every connector.
+func (o *Onelogin) GetConnectors(name string) ([]Connector, error) {
+	query := ConnectorQuery{
+		Limit: strconv.Itoa(DefaultPageSize),
+	}
+	connectors, err := utils.Paginate(func(page int) ([]Connector, error) {
+		query.Page = strconv.Itoa(page)
+		result, err := o.client.GetConnectors(&query)
+		if err != nil {
+			return nil, err
+		}
+		return utils.ConvertToSlice[Connector](result.([]any))
+	}, DefaultPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return connectors, nil
+	}
+
+	needle := strings.ToLower(name)
+	matched := []Connector{}
+	for _, c := range connectors {
+		if strings.Contains(strings.ToLower(c.Name), needle) {
+			matched = append(matched, c)
+		}
+	}
+	return matched, nil
+}
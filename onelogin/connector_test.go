@@ -0,0 +1,75 @@
+package onelogin
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConnectors(t *testing.T) {
+	tests := []struct {
+		name               string
+		filter             string
+		mockResponse       []any
+		mockError          error
+		expectedConnectors []Connector
+		expectedError      error
+	}{
+		{
+			name: "all connectors",
+			mockResponse: []any{
+				map[string]any{"id": float64(110016), "name": "SAML Custom Connector (Advanced)", "auth_method": float64(2)},
+				map[string]any{"id": float64(108419), "name": "OpenId Connect (OIDC)", "auth_method": float64(8)},
+			},
+			expectedConnectors: []Connector{
+				{ID: 110016, Name: "SAML Custom Connector (Advanced)", AuthMethod: 2},
+				{ID: 108419, Name: "OpenId Connect (OIDC)", AuthMethod: 8},
+			},
+		},
+		{
+			name:   "substring filter is case-insensitive",
+			filter: "saml",
+			mockResponse: []any{
+				map[string]any{"id": float64(110016), "name": "SAML Custom Connector (Advanced)", "auth_method": float64(2)},
+				map[string]any{"id": float64(108419), "name": "OpenId Connect (OIDC)", "auth_method": float64(8)},
+			},
+			expectedConnectors: []Connector{
+				{ID: 110016, Name: "SAML Custom Connector (Advanced)", AuthMethod: 2},
+			},
+		},
+		{
+			name:          "error from client",
+			mockError:     assert.AnError,
+			expectedError: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(utils.MockClient)
+			o := &Onelogin{
+				client: mockClient,
+			}
+
+			expectedQuery := &ConnectorQuery{
+				Limit: strconv.Itoa(DefaultPageSize),
+				Page:  "1",
+			}
+			mockClient.On("GetConnectors", expectedQuery).Return(tt.mockResponse, tt.mockError)
+
+			connectors, err := o.GetConnectors(tt.filter)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedConnectors, connectors)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
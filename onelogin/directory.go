@@ -0,0 +1,166 @@
+package onelogin
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Directory is a directory (AD, LDAP, ...) seen in the events of a period
+type Directory struct {
+	ID            int32      `json:"id"`
+	SyncRuns      int        `json:"sync_runs"`
+	Events        int        `json:"events"`
+	Errors        int        `json:"errors"`
+	LastSyncRunID int32      `json:"last_sync_run_id,omitempty"`
+	LastSeen      *time.Time `json:"last_seen,omitempty"`
+}
+
+// DirectorySyncRun summarizes what one directory sync run did, from its events
+type DirectorySyncRun struct {
+	DirectoryID int32      `json:"directory_id"`
+	SyncRunID   int32      `json:"sync_run_id"`
+	Start       *time.Time `json:"start,omitempty"`
+	End         *time.Time `json:"end,omitempty"`
+	Events      int        `json:"events"`
+	Created     int        `json:"created"`
+	Updated     int        `json:"updated"`
+	Suspended   int        `json:"suspended"`
+	Reactivated int        `json:"reactivated"`
+	Deleted     int        `json:"deleted"`
+	Errors      int        `json:"errors"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// isErrorEvent reports whether an event records a failure
+func isErrorEvent(e Event) bool {
+	name := strings.ToUpper(e.EventType)
+	return e.ErrorDescription != "" || strings.Contains(name, "FAIL") || strings.Contains(name, "ERROR")
+}
+
+// count adds an event to the run's counters by the kind of change its event
+// type name describes
+func (r *DirectorySyncRun) count(e Event) {
+	r.Events++
+	if e.CreatedAt != nil {
+		t := e.CreatedAt.UTC()
+		if r.Start == nil || t.Before(*r.Start) {
+			r.Start = &t
+		}
+		if r.End == nil || t.After(*r.End) {
+			r.End = &t
+		}
+	}
+
+	name := strings.ToUpper(e.EventType)
+	switch {
+	case isErrorEvent(e):
+		r.Errors++
+		if r.End == nil || e.CreatedAt == nil || !e.CreatedAt.Before(*r.End) {
+			r.LastError = cmp.Or(e.ErrorDescription, e.CustomMessage, e.EventType)
+		}
+	case strings.Contains(name, "UNSUSPENDED"), strings.Contains(name, "REACTIVATED"):
+		r.Reactivated++
+	case strings.Contains(name, "SUSPENDED"):
+		r.Suspended++
+	case strings.Contains(name, "CREATED"):
+		r.Created++
+	case strings.Contains(name, "DELETED"):
+		r.Deleted++
+	case strings.Contains(name, "UPDATED"):
+		r.Updated++
+	}
+}
+
+// SummarizeSyncRuns groups events by directory sync run and counts the users
+// created, updated, suspended, reactivated and deleted and the errors in
+// each. Events that are not part of a sync run are ignored. Runs are sorted
+// newest first.
+func SummarizeSyncRuns(events iter.Seq2[Event, error]) ([]DirectorySyncRun, error) {
+	type runKey struct{ directoryID, syncRunID int32 }
+	runs := map[runKey]*DirectorySyncRun{}
+	for e, err := range events {
+		if err != nil {
+			return nil, err
+		}
+		if e.DirectorySyncRunID == 0 {
+			continue
+		}
+		key := runKey{e.DirectoryID, e.DirectorySyncRunID}
+		run, ok := runs[key]
+		if !ok {
+			run = &DirectorySyncRun{DirectoryID: e.DirectoryID, SyncRunID: e.DirectorySyncRunID}
+			runs[key] = run
+		}
+		run.count(e)
+	}
+
+	result := []DirectorySyncRun{}
+	for _, run := range runs {
+		result = append(result, *run)
+	}
+	slices.SortFunc(result, func(a, b DirectorySyncRun) int {
+		return cmp.Or(compareTimes(b.Start, a.Start), cmp.Compare(b.SyncRunID, a.SyncRunID), cmp.Compare(a.DirectoryID, b.DirectoryID))
+	})
+	return result, nil
+}
+
+// SummarizeDirectories lists the directories that appear in the events with
+// the number of sync runs, events and errors of each, sorted by ID
+func SummarizeDirectories(events iter.Seq2[Event, error]) ([]Directory, error) {
+	directories := map[int32]*Directory{}
+	runs := map[int32]map[int32]bool{}
+	for e, err := range events {
+		if err != nil {
+			return nil, err
+		}
+		if e.DirectoryID == 0 {
+			continue
+		}
+		d, ok := directories[e.DirectoryID]
+		if !ok {
+			d = &Directory{ID: e.DirectoryID}
+			directories[e.DirectoryID] = d
+			runs[e.DirectoryID] = map[int32]bool{}
+		}
+		d.Events++
+		if isErrorEvent(e) {
+			d.Errors++
+		}
+		if e.DirectorySyncRunID != 0 {
+			runs[e.DirectoryID][e.DirectorySyncRunID] = true
+		}
+		if e.CreatedAt != nil && (d.LastSeen == nil || e.CreatedAt.After(*d.LastSeen)) {
+			t := e.CreatedAt.UTC()
+			d.LastSeen = &t
+			if e.DirectorySyncRunID != 0 {
+				d.LastSyncRunID = e.DirectorySyncRunID
+			}
+		}
+	}
+
+	result := []Directory{}
+	for id, d := range directories {
+		d.SyncRuns = len(runs[id])
+		result = append(result, *d)
+	}
+	slices.SortFunc(result, func(a, b Directory) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return result, nil
+}
+
+// compareTimes compares two optional times, ordering nil first
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
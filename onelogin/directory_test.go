@@ -0,0 +1,90 @@
+package onelogin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func directoryTestEvents() []Event {
+	at := func(minutes int) *time.Time {
+		t := time.Date(2026, 10, 19, 3, minutes, 0, 0, time.UTC)
+		return &t
+	}
+	return []Event{
+		{ID: 9, CreatedAt: at(40), DirectoryID: 2, DirectorySyncRunID: 300, EventType: "USER_UPDATED"},
+		{ID: 8, CreatedAt: at(32), DirectoryID: 1, DirectorySyncRunID: 101, EventType: "DIRECTORY_SYNC_FAILED", ErrorDescription: "LDAP bind failed"},
+		{ID: 7, CreatedAt: at(31), DirectoryID: 1, DirectorySyncRunID: 101, EventType: "USER_CREATED"},
+		{ID: 6, CreatedAt: at(30), DirectoryID: 1, DirectorySyncRunID: 101, EventType: "USER_UNSUSPENDED"},
+		{ID: 5, CreatedAt: at(5), DirectoryID: 1, DirectorySyncRunID: 100, EventType: "USER_DELETED"},
+		{ID: 4, CreatedAt: at(4), DirectoryID: 1, DirectorySyncRunID: 100, EventType: "USER_SUSPENDED"},
+		{ID: 3, CreatedAt: at(3), DirectoryID: 1, DirectorySyncRunID: 100, EventType: "USER_UPDATED"},
+		{ID: 2, CreatedAt: at(2), DirectoryID: 1, DirectorySyncRunID: 100, EventType: "USER_CREATED"},
+		// Not part of a sync run
+		{ID: 1, CreatedAt: at(1), DirectoryID: 1, EventType: "USER_UPDATED"},
+		{ID: 0, CreatedAt: at(0), EventType: "USER_LOGGED_INTO_ONELOGIN"},
+	}
+}
+
+func TestSummarizeSyncRuns(t *testing.T) {
+	runs, err := SummarizeSyncRuns(eventSeq(directoryTestEvents()))
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+
+	assert.Equal(t, int32(300), runs[0].SyncRunID)
+	assert.Equal(t, 1, runs[0].Updated)
+
+	latest := runs[1]
+	assert.Equal(t, int32(1), latest.DirectoryID)
+	assert.Equal(t, int32(101), latest.SyncRunID)
+	assert.Equal(t, 3, latest.Events)
+	assert.Equal(t, 1, latest.Created)
+	assert.Equal(t, 1, latest.Reactivated)
+	assert.Equal(t, 0, latest.Suspended)
+	assert.Equal(t, 1, latest.Errors)
+	assert.Equal(t, "LDAP bind failed", latest.LastError)
+	assert.Equal(t, 30, latest.Start.Minute())
+	assert.Equal(t, 32, latest.End.Minute())
+
+	earlier := runs[2]
+	assert.Equal(t, int32(100), earlier.SyncRunID)
+	assert.Equal(t, DirectorySyncRun{
+		DirectoryID: 1,
+		SyncRunID:   100,
+		Start:       earlier.Start,
+		End:         earlier.End,
+		Events:      4,
+		Created:     1,
+		Updated:     1,
+		Suspended:   1,
+		Deleted:     1,
+	}, earlier)
+
+	t.Run("stream error", func(t *testing.T) {
+		seq := func(yield func(Event, error) bool) {
+			yield(Event{}, errors.New("boom"))
+		}
+		_, err := SummarizeSyncRuns(seq)
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestSummarizeDirectories(t *testing.T) {
+	directories, err := SummarizeDirectories(eventSeq(directoryTestEvents()))
+	require.NoError(t, err)
+	require.Len(t, directories, 2)
+
+	d := directories[0]
+	assert.Equal(t, int32(1), d.ID)
+	assert.Equal(t, 2, d.SyncRuns)
+	assert.Equal(t, 8, d.Events)
+	assert.Equal(t, 1, d.Errors)
+	assert.Equal(t, int32(101), d.LastSyncRunID)
+	assert.Equal(t, 32, d.LastSeen.Minute())
+
+	assert.Equal(t, int32(2), directories[1].ID)
+	assert.Equal(t, 1, directories[1].SyncRuns)
+}
@@ -0,0 +1,116 @@
+package onelogin
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pepabo/onecli/utils"
+)
+
+// EventDetails is an event together with the objects its IDs refer to
+type EventDetails struct {
+	Event `json:",inline"`
+	User  *User `json:"user,omitempty"`
+	Actor *User `json:"actor,omitempty"`
+	App   *App  `json:"app,omitempty"`
+	Role  *Role `json:"role,omitempty"`
+}
+
+// GetEvent retrieves a single event by ID
+func (o *Onelogin) GetEvent(eventID int) (Event, error) {
+	result, err := o.client.GetEventByID(eventID)
+	if err != nil {
+		return Event{}, err
+	}
+	data, ok := result.(map[string]any)
+	if !ok {
+		return Event{}, fmt.Errorf("unexpected response type from get event: %T", result)
+	}
+	response, err := convertToEventsResponse(data)
+	if err != nil {
+		return Event{}, err
+	}
+	if len(response.Data) == 0 {
+		return Event{}, fmt.Errorf("event %d not found", eventID)
+	}
+
+	event := response.Data[0]
+	eventTypes, err := o.GetEventTypes()
+	if err != nil {
+		return Event{}, err
+	}
+	if name, ok := EventTypeIDNameMap(eventTypes)[event.EventTypeID]; ok {
+		event.EventType = name
+	}
+	return event, nil
+}
+
+// GetEventDetails retrieves a single event and expands the user, actor, app
+// and role it refers to. References to objects that no longer exist are
+// left empty.
+func (o *Onelogin) GetEventDetails(eventID int) (EventDetails, error) {
+	event, err := o.GetEvent(eventID)
+	if err != nil {
+		return EventDetails{}, err
+	}
+	details := EventDetails{Event: event}
+
+	if details.User, err = o.getUserByID(event.UserID); err != nil {
+		return EventDetails{}, fmt.Errorf("error getting user %d: %v", event.UserID, err)
+	}
+	if event.ActorUserID == event.UserID {
+		details.Actor = details.User
+	} else if details.Actor, err = o.getUserByID(event.ActorUserID); err != nil {
+		return EventDetails{}, fmt.Errorf("error getting actor %d: %v", event.ActorUserID, err)
+	}
+
+	if event.AppID != 0 {
+		app, err := o.GetApp(int(event.AppID))
+		if err != nil && !utils.IsNotFoundError(err) {
+			return EventDetails{}, fmt.Errorf("error getting app %d: %v", event.AppID, err)
+		}
+		if err == nil {
+			details.App = &app
+		}
+	}
+
+	if event.RoleID != 0 {
+		id := strconv.Itoa(int(event.RoleID))
+		result, err := o.client.GetRoles(&RoleQuery{ID: &id})
+		if err != nil {
+			return EventDetails{}, fmt.Errorf("error getting role %d: %v", event.RoleID, err)
+		}
+		items, ok := result.([]any)
+		if !ok {
+			return EventDetails{}, fmt.Errorf("unexpected response type from get roles: %T", result)
+		}
+		roles, err := utils.ConvertToSlice[Role](items)
+		if err != nil {
+			return EventDetails{}, err
+		}
+		if len(roles) > 0 {
+			details.Role = &roles[0]
+		}
+	}
+
+	return details, nil
+}
+
+// getUserByID returns the user with the ID, or nil if id is zero or the user
+// does not exist
+func (o *Onelogin) getUserByID(id int32) (*User, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	ids := strconv.Itoa(int(id))
+	users, err := o.GetUsers(UserQuery{UserIDs: &ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
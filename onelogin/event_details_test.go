@@ -0,0 +1,106 @@
+package onelogin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventDetails(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(72), "name": "USER_ASSIGNED_ROLE"}},
+	}, nil)
+	mockClient.On("GetEventByID", 100).Return(map[string]any{
+		"data": []any{map[string]any{
+			"id":            float64(100),
+			"event_type_id": float64(72),
+			"user_id":       float64(1),
+			"actor_user_id": float64(2),
+			"app_id":        float64(3),
+			"role_id":       float64(4),
+		}},
+	}, nil)
+
+	userIDs, actorIDs := "1", "2"
+	mockClient.On("GetUsers", &models.UserQuery{Page: "1", UserIDs: &userIDs}).Return([]any{
+		map[string]any{"id": float64(1), "email": "alice@example.com"},
+	}, nil)
+	mockClient.On("GetUsers", &models.UserQuery{Page: "1", UserIDs: &actorIDs}).Return([]any{
+		map[string]any{"id": float64(2), "email": "admin@example.com"},
+	}, nil)
+	mockClient.On("GetAppByID", 3).Return(map[string]any{"id": float64(3), "name": "Slack"}, nil)
+	roleID := "4"
+	mockClient.On("GetRoles", &RoleQuery{ID: &roleID}).Return([]any{
+		map[string]any{"id": float64(4), "name": "Admins"},
+	}, nil)
+
+	details, err := o.GetEventDetails(100)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(100), details.ID)
+	assert.Equal(t, "USER_ASSIGNED_ROLE", details.EventType)
+	require.NotNil(t, details.User)
+	assert.Equal(t, "alice@example.com", details.User.Email)
+	require.NotNil(t, details.Actor)
+	assert.Equal(t, "admin@example.com", details.Actor.Email)
+	require.NotNil(t, details.App)
+	assert.Equal(t, "Slack", *details.App.Name)
+	require.NotNil(t, details.Role)
+	assert.Equal(t, "Admins", *details.Role.Name)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetEventDetailsMissingReferences(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{"data": []any{}}, nil)
+	mockClient.On("GetEventByID", 100).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(100), "user_id": float64(1), "actor_user_id": float64(1), "app_id": float64(3)}},
+	}, nil)
+	userIDs := "1"
+	// The user has been deleted
+	mockClient.On("GetUsers", &models.UserQuery{Page: "1", UserIDs: &userIDs}).Return([]any{}, nil).Once()
+	mockClient.On("GetAppByID", 3).Return(nil, errors.New("request failed with status: 404"))
+
+	details, err := o.GetEventDetails(100)
+	require.NoError(t, err)
+	assert.Nil(t, details.User)
+	assert.Nil(t, details.Actor)
+	assert.Nil(t, details.App)
+	assert.Nil(t, details.Role)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetEventNotFound(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+	mockClient.On("GetEventByID", 1).Return(map[string]any{"data": []any{}}, nil)
+
+	_, err := o.GetEvent(1)
+	assert.EqualError(t, err, "event 1 not found")
+}
+
+func TestGetEventUnexpectedResponse(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+	mockClient.On("GetEventByID", 1).Return([]any{}, nil)
+
+	_, err := o.GetEvent(1)
+	assert.EqualError(t, err, "unexpected response type from get event: []interface {}")
+}
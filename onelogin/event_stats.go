@@ -0,0 +1,189 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventStatsOptions controls how AggregateEvents groups events
+type EventStatsOptions struct {
+	// GroupBy lists the event fields to group by, by their JSON names
+	GroupBy []string
+	// Bucket splits the counts into time buckets of this size when non-zero
+	Bucket time.Duration
+	// Top keeps only the groups with the highest counts when positive
+	Top int
+}
+
+// EventStat is the number of events in one group, and one time bucket if
+// bucketing is enabled
+type EventStat struct {
+	Bucket    *time.Time        `json:"bucket,omitempty"`
+	Group     map[string]string `json:"group"`
+	Count     int               `json:"count"`
+	FirstSeen *time.Time        `json:"first_seen,omitempty"`
+	LastSeen  *time.Time        `json:"last_seen,omitempty"`
+}
+
+// eventFieldIndex maps the JSON name of each Event field to its index
+var eventFieldIndex = func() map[string]int {
+	m := map[string]int{}
+	t := reflect.TypeFor[Event]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" {
+			m[name] = i
+		}
+	}
+	return m
+}()
+
+// EventFieldNames returns the event fields that can be grouped by
+func EventFieldNames() []string {
+	return slices.Sorted(maps.Keys(eventFieldIndex))
+}
+
+// eventFieldValue returns the value of an event field by its JSON name
+func eventFieldValue(e Event, name string) string {
+	v := reflect.ValueOf(e).Field(eventFieldIndex[name])
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int32:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		if t, ok := v.Interface().(*time.Time); ok {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// AggregateEvents counts the events of the stream per group. Without a
+// bucket the result is sorted by count, highest first; with a bucket it is
+// sorted by bucket and then by count.
+func AggregateEvents(events iter.Seq2[Event, error], opts EventStatsOptions) ([]EventStat, error) {
+	for _, name := range opts.GroupBy {
+		if _, ok := eventFieldIndex[name]; !ok {
+			return nil, fmt.Errorf("unknown event field %q (available: %s)", name, strings.Join(EventFieldNames(), ", "))
+		}
+	}
+
+	type statKey struct {
+		bucket time.Time
+		group  string
+	}
+	stats := map[statKey]*EventStat{}
+	groupTotals := map[string]int{}
+
+	for e, err := range events {
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, len(opts.GroupBy))
+		for i, name := range opts.GroupBy {
+			values[i] = eventFieldValue(e, name)
+		}
+		key := statKey{group: strings.Join(values, "\x00")}
+		if opts.Bucket > 0 && e.CreatedAt != nil {
+			key.bucket = e.CreatedAt.UTC().Truncate(opts.Bucket)
+		}
+
+		stat, ok := stats[key]
+		if !ok {
+			stat = &EventStat{Group: map[string]string{}}
+			for i, name := range opts.GroupBy {
+				stat.Group[name] = values[i]
+			}
+			if opts.Bucket > 0 && e.CreatedAt != nil {
+				stat.Bucket = &key.bucket
+			}
+			stats[key] = stat
+		}
+		stat.Count++
+		groupTotals[key.group]++
+
+		if e.CreatedAt != nil {
+			t := e.CreatedAt.UTC()
+			if stat.FirstSeen == nil || t.Before(*stat.FirstSeen) {
+				stat.FirstSeen = &t
+			}
+			if stat.LastSeen == nil || t.After(*stat.LastSeen) {
+				stat.LastSeen = &t
+			}
+		}
+	}
+
+	// Rank the groups by their total count to pick the top N
+	groups := slices.Collect(maps.Keys(groupTotals))
+	slices.SortFunc(groups, func(a, b string) int {
+		return cmp.Or(cmp.Compare(groupTotals[b], groupTotals[a]), cmp.Compare(a, b))
+	})
+	if opts.Top > 0 && len(groups) > opts.Top {
+		groups = groups[:opts.Top]
+	}
+	rank := map[string]int{}
+	for i, g := range groups {
+		rank[g] = i
+	}
+
+	result := []EventStat{}
+	keys := []statKey{}
+	for key := range stats {
+		if _, ok := rank[key.group]; ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b statKey) int {
+		return cmp.Or(a.bucket.Compare(b.bucket), cmp.Compare(rank[a.group], rank[b.group]))
+	})
+	for _, key := range keys {
+		result = append(result, *stats[key])
+	}
+	return result, nil
+}
+
+// EventStatsRows returns the stats as table rows for CSV and XLSX output,
+// with one column per group-by field
+func EventStatsRows(stats []EventStat, groupBy []string, bucket bool) [][]string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	var header []string
+	if bucket {
+		header = append(header, "bucket")
+	}
+	header = append(header, groupBy...)
+	header = append(header, "count", "first_seen", "last_seen")
+
+	rows := [][]string{header}
+	for _, s := range stats {
+		var row []string
+		if bucket {
+			row = append(row, formatTime(s.Bucket))
+		}
+		for _, name := range groupBy {
+			row = append(row, s.Group[name])
+		}
+		row = append(row, strconv.Itoa(s.Count), formatTime(s.FirstSeen), formatTime(s.LastSeen))
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,89 @@
+package onelogin
+
+import (
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventSeq(events []Event) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		for _, e := range events {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestAggregateEvents(t *testing.T) {
+	at := func(h, m int) *time.Time {
+		t := time.Date(2026, 10, 19, h, m, 0, 0, time.UTC)
+		return &t
+	}
+	events := []Event{
+		{ID: 1, EventType: "LOGIN_FAILED", UserName: "alice", CreatedAt: at(9, 10)},
+		{ID: 2, EventType: "LOGIN_FAILED", UserName: "alice", CreatedAt: at(10, 5)},
+		{ID: 3, EventType: "LOGIN_FAILED", UserName: "bob", CreatedAt: at(9, 30)},
+		{ID: 4, EventType: "LOGIN", UserName: "alice", CreatedAt: at(9, 0)},
+		{ID: 5, EventType: "LOGIN_FAILED", UserName: "alice", CreatedAt: at(9, 50)},
+	}
+
+	t.Run("group by", func(t *testing.T) {
+		stats, err := AggregateEvents(eventSeq(events), EventStatsOptions{GroupBy: []string{"event_type", "user_name"}})
+		require.NoError(t, err)
+		require.Len(t, stats, 3)
+
+		assert.Equal(t, map[string]string{"event_type": "LOGIN_FAILED", "user_name": "alice"}, stats[0].Group)
+		assert.Equal(t, 3, stats[0].Count)
+		assert.Equal(t, at(9, 10), stats[0].FirstSeen)
+		assert.Equal(t, at(10, 5), stats[0].LastSeen)
+		assert.Nil(t, stats[0].Bucket)
+
+		// Ties are ordered by the group values
+		assert.Equal(t, "LOGIN", stats[1].Group["event_type"])
+		assert.Equal(t, "bob", stats[2].Group["user_name"])
+	})
+
+	t.Run("top", func(t *testing.T) {
+		stats, err := AggregateEvents(eventSeq(events), EventStatsOptions{GroupBy: []string{"user_name"}, Top: 1})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, "alice", stats[0].Group["user_name"])
+		assert.Equal(t, 4, stats[0].Count)
+	})
+
+	t.Run("bucket", func(t *testing.T) {
+		stats, err := AggregateEvents(eventSeq(events), EventStatsOptions{GroupBy: []string{"user_name"}, Bucket: time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"bucket", "user_name", "count", "first_seen", "last_seen"},
+			{"2026-10-19T09:00:00Z", "alice", "3", "2026-10-19T09:00:00Z", "2026-10-19T09:50:00Z"},
+			{"2026-10-19T09:00:00Z", "bob", "1", "2026-10-19T09:30:00Z", "2026-10-19T09:30:00Z"},
+			{"2026-10-19T10:00:00Z", "alice", "1", "2026-10-19T10:05:00Z", "2026-10-19T10:05:00Z"},
+		}, EventStatsRows(stats, []string{"user_name"}, true))
+	})
+
+	t.Run("no group", func(t *testing.T) {
+		stats, err := AggregateEvents(eventSeq(events), EventStatsOptions{})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.Equal(t, 5, stats[0].Count)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := AggregateEvents(eventSeq(events), EventStatsOptions{GroupBy: []string{"nope"}})
+		assert.ErrorContains(t, err, `unknown event field "nope"`)
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		seq := func(yield func(Event, error) bool) {
+			yield(Event{}, assert.AnError)
+		}
+		_, err := AggregateEvents(seq, EventStatsOptions{})
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -1,7 +1,12 @@
 package onelogin
 
 import (
+	"cmp"
 	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/pepabo/onecli/utils"
 )
 
 // EventType represents an OneLogin event type
@@ -24,20 +29,19 @@ type EventTypesResponse struct {
 // GetEventTypes retrieves event types from OneLogin (with caching)
 func (o *Onelogin) GetEventTypes() ([]EventType, error) {
 	o.eventTypesCacheOnce.Do(func() {
-		result, err := o.client.GetEventTypes(nil)
-		if err != nil {
-			o.eventTypesCacheErr = err
-			return
-		}
+		o.eventTypesCache, o.eventTypesCacheErr = utils.Cached(o.cache, "event_types", func() ([]EventType, error) {
+			result, err := o.client.GetEventTypes(nil)
+			if err != nil {
+				return nil, err
+			}
 
-		// Convert the result to EventTypesResponse
-		response, err := convertToEventTypesResponse(result.(map[string]any))
-		if err != nil {
-			o.eventTypesCacheErr = err
-			return
-		}
-
-		o.eventTypesCache = response.Data
+			// Convert the result to EventTypesResponse
+			response, err := convertToEventTypesResponse(result.(map[string]any))
+			if err != nil {
+				return nil, err
+			}
+			return response.Data, nil
+		})
 	})
 	return o.eventTypesCache, o.eventTypesCacheErr
 }
@@ -74,3 +78,50 @@ func EventTypeNameIDMap(eventTypes []EventType) map[string]int32 {
 	}
 	return m
 }
+
+// FilterEventTypes returns the event types whose name or description
+// contains search, ignoring case, and whose ID is in ids. An empty search or
+// ids does not filter.
+func FilterEventTypes(eventTypes []EventType, search string, ids []int32) []EventType {
+	search = strings.ToLower(search)
+	filtered := []EventType{}
+	for _, et := range eventTypes {
+		if len(ids) > 0 && !slices.Contains(ids, et.ID) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(et.Name), search) && !strings.Contains(strings.ToLower(et.Description), search) {
+			continue
+		}
+		filtered = append(filtered, et)
+	}
+	return filtered
+}
+
+// SuggestEventTypeNames returns up to n event type names closest to name by
+// edit distance, ignoring case. Names that are too different to be a typo
+// are not suggested: one edit is allowed per five characters, up to three,
+// as many event type names share long prefixes such as USER_.
+func SuggestEventTypeNames(eventTypes []EventType, name string, n int) []string {
+	name = strings.ToUpper(name)
+	limit := min(3, max(1, len(name)/5))
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for _, et := range eventTypes {
+		if d := utils.EditDistance(name, strings.ToUpper(et.Name)); d <= limit {
+			candidates = append(candidates, candidate{et.Name, d})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.name, b.name))
+	})
+
+	var names []string
+	for _, c := range candidates[:min(n, len(candidates))] {
+		names = append(names, c.name)
+	}
+	return names
+}
@@ -2,9 +2,11 @@ package onelogin
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pepabo/onecli/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetEventTypes(t *testing.T) {
@@ -234,3 +236,127 @@ func TestEventTypeStruct(t *testing.T) {
 	assert.Equal(t, "Test Event Type", eventType.Name)
 	assert.Equal(t, "Test event type description", eventType.Description)
 }
+
+func TestGetEventTypesDiskCache(t *testing.T) {
+	cache := utils.NewCache(t.TempDir(), time.Hour)
+
+	mockClient := new(utils.MockClient)
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(5), "name": "USER_LOGGED_INTO_ONELOGIN"}},
+	}, nil).Once()
+	first := &Onelogin{client: mockClient}
+	first.SetCache(cache)
+
+	eventTypes, err := first.GetEventTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{{ID: 5, Name: "USER_LOGGED_INTO_ONELOGIN"}}, eventTypes)
+	mockClient.AssertExpectations(t)
+
+	// A later process reads the event types from disk without calling the API
+	second := &Onelogin{client: new(utils.MockClient)}
+	second.SetCache(cache)
+
+	eventTypes, err = second.GetEventTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{{ID: 5, Name: "USER_LOGGED_INTO_ONELOGIN"}}, eventTypes)
+}
+
+func TestFilterEventTypes(t *testing.T) {
+	eventTypes := []EventType{
+		{ID: 5, Name: "USER_LOGGED_INTO_ONELOGIN", Description: "User logged into OneLogin"},
+		{ID: 6, Name: "USER_FAILED_ONELOGIN_AUTHENTICATION", Description: "Failed authentication"},
+		{ID: 11, Name: "USER_CHANGED_PASSWORD", Description: "User changed their password"},
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		ids      []int32
+		expected []int32
+	}{
+		{name: "no filter", expected: []int32{5, 6, 11}},
+		{name: "search by name ignoring case", search: "logged", expected: []int32{5}},
+		{name: "search by description", search: "authentication", expected: []int32{6}},
+		{name: "by IDs", ids: []int32{6, 11}, expected: []int32{6, 11}},
+		{name: "search and IDs", search: "user", ids: []int32{5, 6}, expected: []int32{5, 6}},
+		{name: "no match", search: "role", expected: []int32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int32{}
+			for _, et := range FilterEventTypes(eventTypes, tt.search, tt.ids) {
+				ids = append(ids, et.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestSuggestEventTypeNames(t *testing.T) {
+	eventTypes := []EventType{
+		{ID: 5, Name: "USER_LOGGED_INTO_ONELOGIN"},
+		{ID: 8, Name: "USER_LOGGED_INTO_APP"},
+		{ID: 11, Name: "USER_CHANGED_PASSWORD"},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "typo", input: "USER_LOGED_INTO_APP", expected: []string{"USER_LOGGED_INTO_APP"}},
+		{name: "lower case", input: "user_changed_pasword", expected: []string{"USER_CHANGED_PASSWORD"}},
+		{name: "closest only", input: "USER_LOGGED_INTO_ONELOGN", expected: []string{"USER_LOGGED_INTO_ONELOGIN"}},
+		{name: "too different", input: "ROLE_CREATED", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SuggestEventTypeNames(eventTypes, tt.input, 3))
+		})
+	}
+}
+
+func TestSuggestEventTypeNamesLargeCatalogue(t *testing.T) {
+	// A catalogue the size of a real organization's, where most names share
+	// the USER_ prefix and differ in a word or two
+	eventTypes := []EventType{
+		{Name: "USER_LOGGED_INTO_ONELOGIN"},
+		{Name: "USER_LOGGED_OUT_OF_ONELOGIN"},
+		{Name: "USER_FAILED_ONELOGIN_AUTHENTICATION"},
+		{Name: "USER_LOCKED_OUT"},
+		{Name: "USER_UNLOCKED"},
+		{Name: "USER_CHANGED_PASSWORD"},
+		{Name: "USER_REQUESTED_PASSWORD_RESET"},
+	}
+	objects := []string{
+		"APP", "ROLE", "GROUP", "POLICY", "MAPPING", "OTP_DEVICE", "CERTIFICATE", "DIRECTORY",
+		"API_CREDENTIAL", "CONNECTOR", "PRIVILEGE", "TRUSTED_IDP", "SMART_HOOK", "BRANDING", "CUSTOM_ATTRIBUTE",
+	}
+	verbs := []string{"CREATED", "DELETED", "UPDATED", "ASSIGNED", "UNASSIGNED", "ENABLED", "DISABLED", "REQUESTED", "FAILED", "REMOVED", "ADDED"}
+	for _, object := range objects {
+		for _, verb := range verbs {
+			eventTypes = append(eventTypes, EventType{Name: object + "_" + verb}, EventType{Name: "USER_" + verb + "_" + object})
+		}
+	}
+	require.Greater(t, len(eventTypes), 300)
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "USER_LOGED_INTO_ONELOGIN", expected: []string{"USER_LOGGED_INTO_ONELOGIN"}},
+		{input: "USER_ASSIGNED_ROEL", expected: []string{"USER_ASSIGNED_ROLE"}},
+		{input: "ROLE_CRAETED", expected: []string{"ROLE_CREATED"}},
+		{input: "USER_LOGGED_INTO_SALESFORCE", expected: nil},
+		{input: "USER_UPDATED_NICKNAME", expected: nil},
+		{input: "USER_PASSWORD_EXPIRED", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SuggestEventTypeNames(eventTypes, tt.input, 1))
+			for _, name := range SuggestEventTypeNames(eventTypes, tt.input, len(eventTypes)) {
+				assert.LessOrEqual(t, utils.EditDistance(tt.input, name), 3, name)
+			}
+		})
+	}
+}
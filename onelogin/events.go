@@ -1,9 +1,17 @@
 package onelogin
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
+	"iter"
+	"net/netip"
+	"slices"
 	"strconv"
 	"time"
+
+	"github.com/pepabo/onecli/utils"
 )
 
 // Event represents an OneLogin event
@@ -48,6 +56,28 @@ type Event struct {
 	UserName             string     `json:"user_name,omitempty"`
 }
 
+// SIEMRecord returns the fields written by the CEF, LEEF and syslog output formats
+func (e Event) SIEMRecord() utils.SIEMRecord {
+	r := utils.SIEMRecord{
+		ID:        strconv.FormatUint(e.ID, 10),
+		TypeID:    e.EventTypeID,
+		TypeName:  e.EventType,
+		ActorID:   e.ActorUserID,
+		ActorName: e.ActorUserName,
+		UserID:    e.UserID,
+		UserName:  e.UserName,
+		SourceIP:  e.IPAddr,
+		AppID:     e.AppID,
+		AppName:   e.AppName,
+		RiskScore: e.RiskScore,
+		Message:   cmp.Or(e.CustomMessage, e.ErrorDescription),
+	}
+	if e.CreatedAt != nil {
+		r.Time = *e.CreatedAt
+	}
+	return r
+}
+
 type EventsResponse struct {
 	Status struct {
 		Error   bool   `json:"error"`
@@ -77,6 +107,26 @@ type EventsQuery struct {
 	Since       *string `json:"since,omitempty"`
 	Until       *string `json:"until,omitempty"`
 	UserID      *string `json:"user_id,omitempty"`
+
+	// The events API cannot filter by these, so they are applied to each
+	// page after it is fetched
+	AppIDs  []int32        `json:"-"`
+	IPNets  []netip.Prefix `json:"-"`
+	RiskMin int32          `json:"-"`
+}
+
+// matches reports whether an event passes the client-side filters
+func (q EventsQuery) matches(e Event) bool {
+	if len(q.AppIDs) > 0 && !slices.Contains(q.AppIDs, e.AppID) {
+		return false
+	}
+	if len(q.IPNets) > 0 {
+		addr, err := netip.ParseAddr(e.IPAddr)
+		if err != nil || !slices.ContainsFunc(q.IPNets, func(p netip.Prefix) bool { return p.Contains(addr.Unmap()) }) {
+			return false
+		}
+	}
+	return e.RiskScore >= q.RiskMin
 }
 
 // GetKeyValidators returns the validators for the query parameters
@@ -98,14 +148,54 @@ func (q EventsQuery) GetKeyValidators() map[string]func(any) bool {
 
 // ListEvents retrieves events from OneLogin
 func (o *Onelogin) ListEvents(query EventsQuery) ([]Event, error) {
+	events := []Event{}
+	err := o.eachEventPage(query, func(page []Event, _ string) error {
+		events = append(events, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// errStopIteration is returned from a page callback when the consumer of an
+// iterator stops early
+var errStopIteration = errors.New("stop iteration")
+
+// Events returns an iterator over the events matching the query. Pages are
+// fetched lazily as the iterator is consumed, so callers can process a large
+// range without holding every event in memory. A fetch error is yielded once
+// as the last element.
+func (o *Onelogin) Events(query EventsQuery) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		err := o.eachEventPage(query, func(page []Event, _ string) error {
+			for _, e := range page {
+				if !yield(e, nil) {
+					return errStopIteration
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(Event{}, err)
+		}
+	}
+}
+
+// eachEventPage calls fn with every page of events matching the query, in the
+// order the API returns them (newest first), following AfterCursor until it
+// is empty. fn also receives the cursor of the next page, which is empty on
+// the last page. Paging starts at query.Cursor if it is set. Event type names
+// are filled in before fn is called.
+func (o *Onelogin) eachEventPage(query EventsQuery, fn func(page []Event, nextCursor string) error) error {
 	query.Limit = strconv.Itoa(DefaultPageSize)
 	nextCursor := ""
-	events := []Event{}
 
 	// Get event types mapping for efficient lookup
 	eventTypes, err := o.GetEventTypes()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Create a map for quick lookup of event type names by ID
@@ -116,15 +206,17 @@ func (o *Onelogin) ListEvents(query EventsQuery) ([]Event, error) {
 			query.Cursor = nextCursor
 		}
 
-		result, err := o.client.ListEvents(&query)
+		result, err := utils.Retry(o.retry, func() (any, error) {
+			return o.client.ListEvents(&query)
+		})
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// TODO: Inefficient workaround - using JSON marshaling/unmarshaling as a shortcut for complex type conversion
 		response, err := convertToEventsResponse(result.(map[string]any))
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		// Set event type names for each event
@@ -133,21 +225,132 @@ func (o *Onelogin) ListEvents(query EventsQuery) ([]Event, error) {
 				response.Data[i].EventType = eventTypeName
 			}
 		}
-
-		events = append(events, response.Data...)
+		response.Data = slices.DeleteFunc(response.Data, func(e Event) bool {
+			return !query.matches(e)
+		})
 
 		// Check if AfterCursor is nil before dereferencing
-		if response.Pagination.AfterCursor == nil {
-			break
+		nextCursor = ""
+		if response.Pagination.AfterCursor != nil {
+			nextCursor = *response.Pagination.AfterCursor
+		}
+
+		if err := fn(response.Data, nextCursor); err != nil {
+			return err
 		}
-		nextCursor = *response.Pagination.AfterCursor
 
 		if nextCursor == "" {
 			break
 		}
 	}
 
-	return events, nil
+	return nil
+}
+
+// followEventsLag is how far behind the current time FollowEvents starts a
+// poll when the previous poll found no new events
+const followEventsLag = 5 * time.Minute
+
+// FollowEvents polls OneLogin for new events every interval and calls fn with
+// each event as soon as it is seen, oldest first, until ctx is cancelled or fn
+// returns an error. Polling starts at query.Since, or at the current time if
+// it is not set, and then continues from the newest event fetched so far,
+// whether or not it passed the client-side filters.
+// Cancelling ctx is a clean stop and returns nil.
+func (o *Onelogin) FollowEvents(ctx context.Context, query EventsQuery, interval time.Duration, fn func(Event) error) error {
+	since := time.Now().UTC().Format(time.RFC3339)
+	if query.Since != nil && *query.Since != "" {
+		since = *query.Since
+	}
+	query.Cursor = ""
+
+	// Apply the client-side filters after the start of the next poll has
+	// moved past every fetched event, so that a filter nothing matches does
+	// not keep the polled window growing from the original start
+	filter := query
+	query.AppIDs, query.IPNets, query.RiskMin = nil, nil, 0
+
+	// The API's since filter has second precision and is inclusive, so events
+	// at the boundary come back on the next poll; remember what was emitted.
+	seen := make(map[uint64]time.Time)
+
+	for {
+		pollStart := time.Now().UTC()
+		q := query
+		q.Since = &since
+
+		var fresh []Event
+		err := o.eachEventPage(q, func(page []Event, _ string) error {
+			for _, e := range page {
+				if _, ok := seen[e.ID]; !ok {
+					fresh = append(fresh, e)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		SortEventsChronologically(fresh)
+
+		for _, e := range fresh {
+			createdAt := time.Time{}
+			if e.CreatedAt != nil {
+				createdAt = *e.CreatedAt
+			}
+			seen[e.ID] = createdAt
+			if !filter.matches(e) {
+				continue
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+
+		if n := len(fresh); n > 0 && fresh[n-1].CreatedAt != nil {
+			newest := fresh[n-1].CreatedAt.UTC().Truncate(time.Second)
+			since = newest.Format(time.RFC3339)
+			for id, t := range seen {
+				if t.Before(newest) {
+					delete(seen, id)
+				}
+			}
+		} else if n == 0 {
+			// Nothing new, possibly because a server-side filter such as the
+			// user matches nothing; keep the window from growing while still
+			// allowing for events that are stored a little late
+			if t, err := time.Parse(time.RFC3339, since); err == nil && t.Before(pollStart.Add(-followEventsLag)) {
+				since = pollStart.Add(-followEventsLag).Truncate(time.Second).Format(time.RFC3339)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// SortEventsChronologically sorts events oldest first, breaking ties by ID
+func SortEventsChronologically(events []Event) {
+	slices.SortStableFunc(events, func(a, b Event) int {
+		var ta, tb time.Time
+		if a.CreatedAt != nil {
+			ta = *a.CreatedAt
+		}
+		if b.CreatedAt != nil {
+			tb = *b.CreatedAt
+		}
+		if c := ta.Compare(tb); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
 }
 
 func convertToEventsResponse(data map[string]any) (*EventsResponse, error) {
@@ -0,0 +1,91 @@
+package onelogin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ListEventsParallel retrieves the events between query.Since and
+// query.Until (or now) by splitting the range into n windows and paging each
+// window concurrently. The API's time filters are inclusive at second
+// precision, so windows share their boundary second; duplicates are dropped
+// by event ID. The result is sorted oldest first.
+func (o *Onelogin) ListEventsParallel(query EventsQuery, n int, now time.Time) ([]Event, error) {
+	if query.Since == nil || *query.Since == "" {
+		return nil, fmt.Errorf("parallel fetching requires a start time")
+	}
+	since, err := time.Parse(time.RFC3339, *query.Since)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since: %v", err)
+	}
+	until := now.UTC().Truncate(time.Second)
+	if query.Until != nil && *query.Until != "" {
+		if until, err = time.Parse(time.RFC3339, *query.Until); err != nil {
+			return nil, fmt.Errorf("invalid until: %v", err)
+		}
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	windows := splitTimeRange(since, until, n)
+	results := make([][]Event, len(windows))
+	errs := make([]error, len(windows))
+
+	var wg sync.WaitGroup
+	for i, w := range windows {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q := query
+			q.Cursor = ""
+			s, u := w[0].Format(time.RFC3339), w[1].Format(time.RFC3339)
+			q.Since, q.Until = &s, &u
+			errs[i] = o.eachEventPage(q, func(page []Event, _ string) error {
+				results[i] = append(results[i], page...)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[uint64]bool{}
+	events := []Event{}
+	for _, page := range results {
+		for _, e := range page {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				events = append(events, e)
+			}
+		}
+	}
+	SortEventsChronologically(events)
+	return events, nil
+}
+
+// splitTimeRange splits [since, until] into at most n windows of whole
+// seconds. Adjacent windows share their boundary.
+func splitTimeRange(since, until time.Time, n int) [][2]time.Time {
+	total := until.Sub(since)
+	n = max(1, min(n, int(total/time.Second)))
+	step := (total / time.Duration(n)).Truncate(time.Second)
+
+	windows := make([][2]time.Time, 0, n)
+	start := since
+	for i := range n {
+		end := start.Add(step)
+		if i == n-1 {
+			end = until
+		}
+		windows = append(windows, [2]time.Time{start, end})
+		start = end
+	}
+	return windows
+}
@@ -0,0 +1,78 @@
+package onelogin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEventsParallel(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+		retry:  utils.RetryOptions{MaxRetries: 1, Backoff: time.Millisecond},
+	}
+
+	window := func(since, until string) any {
+		return mock.MatchedBy(func(q *EventsQuery) bool {
+			return *q.Since == since && *q.Until == until
+		})
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{"data": []any{}}, nil)
+	mockClient.On("ListEvents", window("2026-10-01T00:00:00Z", "2026-10-01T00:00:05Z")).Return(map[string]any{
+		"data": []any{
+			map[string]any{"id": float64(3), "created_at": "2026-10-01T00:00:05Z"},
+			map[string]any{"id": float64(1), "created_at": "2026-10-01T00:00:01Z"},
+		},
+	}, nil)
+	// The second window is rate limited once and then retried
+	mockClient.On("ListEvents", window("2026-10-01T00:00:05Z", "2026-10-01T00:00:10Z")).Return(nil, errors.New("request failed with status: 429")).Once()
+	mockClient.On("ListEvents", window("2026-10-01T00:00:05Z", "2026-10-01T00:00:10Z")).Return(map[string]any{
+		"data": []any{
+			map[string]any{"id": float64(4), "created_at": "2026-10-01T00:00:09Z"},
+			map[string]any{"id": float64(3), "created_at": "2026-10-01T00:00:05Z"},
+		},
+	}, nil).Once()
+
+	since, until := "2026-10-01T00:00:00Z", "2026-10-01T00:00:10Z"
+	events, err := o.ListEventsParallel(EventsQuery{Since: &since, Until: &until}, 2, time.Now())
+	require.NoError(t, err)
+
+	var ids []uint64
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []uint64{1, 3, 4}, ids)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListEventsParallelErrors(t *testing.T) {
+	o := &Onelogin{client: new(utils.MockClient)}
+
+	_, err := o.ListEventsParallel(EventsQuery{}, 4, time.Now())
+	assert.EqualError(t, err, "parallel fetching requires a start time")
+
+	since := "2026-10-01T00:00:00Z"
+	_, err = o.ListEventsParallel(EventsQuery{Since: &since}, 4, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	assert.EqualError(t, err, "since must be before until")
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	windows := splitTimeRange(since, since.Add(10*time.Second), 3)
+	assert.Equal(t, [][2]time.Time{
+		{since, since.Add(3 * time.Second)},
+		{since.Add(3 * time.Second), since.Add(6 * time.Second)},
+		{since.Add(6 * time.Second), since.Add(10 * time.Second)},
+	}, windows)
+
+	// A range shorter than n seconds is not split below one second
+	assert.Len(t, splitTimeRange(since, since.Add(2*time.Second), 8), 2)
+}
@@ -1,12 +1,16 @@
 package onelogin
 
 import (
+	"context"
+	"net/netip"
 	"strconv"
 	"testing"
 	"time"
 
+	utl "github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/utilities"
 	"github.com/pepabo/onecli/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestEventsQuery_GetKeyValidators(t *testing.T) {
@@ -741,3 +745,249 @@ func TestEventStruct(t *testing.T) {
 	assert.Equal(t, int32(123), event.UserID)
 	assert.Equal(t, "testuser", event.UserName)
 }
+
+func TestFollowEvents(t *testing.T) {
+	eventsPage := func(events ...map[string]any) map[string]any {
+		data := make([]any, len(events))
+		for i, e := range events {
+			data[i] = e
+		}
+		return map[string]any{
+			"pagination": map[string]any{"after_cursor": nil},
+			"data":       data,
+		}
+	}
+	event := func(id int, createdAt string) map[string]any {
+		return map[string]any{"id": float64(id), "event_type_id": float64(1), "created_at": createdAt}
+	}
+
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(1), "name": "User Login"}},
+	}, nil)
+
+	since := "2023-01-01T00:00:00Z"
+	// First poll starts at --since; the API returns newest first
+	mockClient.On("ListEvents", mock.MatchedBy(func(q *EventsQuery) bool {
+		return *q.Since == since
+	})).Return(eventsPage(
+		event(2, "2023-01-01T00:00:05.500Z"),
+		event(1, "2023-01-01T00:00:01Z"),
+	), nil).Once()
+	// Later polls continue from the newest event (truncated to the second),
+	// so event 2 comes back and must not be emitted twice
+	mockClient.On("ListEvents", mock.MatchedBy(func(q *EventsQuery) bool {
+		return *q.Since == "2023-01-01T00:00:05Z"
+	})).Return(eventsPage(
+		event(3, "2023-01-01T00:00:07Z"),
+		event(2, "2023-01-01T00:00:05.500Z"),
+	), nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got []uint64
+	err := o.FollowEvents(ctx, EventsQuery{Since: &since}, time.Millisecond, func(e Event) error {
+		got = append(got, e.ID)
+		assert.Equal(t, "User Login", e.EventType)
+		if len(got) == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, got)
+	mockClient.AssertExpectations(t)
+}
+
+func TestFollowEventsNoMatch(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(1), "name": "User Login"}},
+	}, nil)
+
+	since := "2023-01-01T00:00:00Z"
+	// The first poll returns events from other IPs; the client-side filter
+	// is not sent to the API
+	mockClient.On("ListEvents", mock.MatchedBy(func(q *EventsQuery) bool {
+		return *q.Since == since && len(q.IPNets) == 0
+	})).Return(map[string]any{
+		"pagination": map[string]any{"after_cursor": nil},
+		"data": []any{
+			map[string]any{"id": float64(2), "event_type_id": float64(1), "created_at": "2023-01-01T00:00:09Z", "ipaddr": "198.51.100.2"},
+			map[string]any{"id": float64(1), "event_type_id": float64(1), "created_at": "2023-01-01T00:00:01Z", "ipaddr": "198.51.100.1"},
+		},
+	}, nil).Once()
+	// The next poll starts from the newest fetched event even though it did
+	// not match, and finds nothing
+	mockClient.On("ListEvents", mock.MatchedBy(func(q *EventsQuery) bool {
+		return *q.Since == "2023-01-01T00:00:09Z"
+	})).Return(map[string]any{
+		"pagination": map[string]any{"after_cursor": nil},
+		"data":       []any{},
+	}, nil).Once()
+
+	// After an empty poll the window is bounded by the current time
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient.On("ListEvents", mock.MatchedBy(func(q *EventsQuery) bool {
+		t, err := time.Parse(time.RFC3339, *q.Since)
+		return err == nil && time.Since(t) <= followEventsLag+time.Minute
+	})).Run(func(mock.Arguments) { cancel() }).Return(map[string]any{
+		"pagination": map[string]any{"after_cursor": nil},
+		"data":       []any{},
+	}, nil).Once()
+
+	query := EventsQuery{Since: &since, IPNets: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}}
+	err := o.FollowEvents(ctx, query, time.Millisecond, func(e Event) error {
+		t.Errorf("unexpected event %d", e.ID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestSortEventsChronologically(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Second)
+	events := []Event{
+		{ID: 3, CreatedAt: &t2},
+		{ID: 2, CreatedAt: &t1},
+		{ID: 1, CreatedAt: &t1},
+	}
+
+	SortEventsChronologically(events)
+
+	assert.Equal(t, []uint64{1, 2, 3}, []uint64{events[0].ID, events[1].ID, events[2].ID})
+}
+
+func TestEvents(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{
+		"data": []any{map[string]any{"id": float64(1), "name": "User Login"}},
+	}, nil)
+	mockClient.On("ListEvents", &EventsQuery{Limit: strconv.Itoa(DefaultPageSize)}).Return(map[string]any{
+		"pagination": map[string]any{"after_cursor": "cursor123"},
+		"data": []any{
+			map[string]any{"id": float64(2), "event_type_id": float64(1)},
+			map[string]any{"id": float64(1), "event_type_id": float64(1)},
+		},
+	}, nil).Once()
+	mockClient.On("ListEvents", &EventsQuery{Limit: strconv.Itoa(DefaultPageSize), Cursor: "cursor123"}).Return(nil, assert.AnError).Once()
+
+	var ids []uint64
+	var gotErr error
+	for e, err := range o.Events(EventsQuery{}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		assert.Equal(t, "User Login", e.EventType)
+		ids = append(ids, e.ID)
+	}
+
+	assert.Equal(t, []uint64{2, 1}, ids)
+	assert.Equal(t, assert.AnError, gotErr)
+	mockClient.AssertExpectations(t)
+}
+
+func TestEventsStopsEarly(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{"data": []any{}}, nil)
+	mockClient.On("ListEvents", &EventsQuery{Limit: strconv.Itoa(DefaultPageSize)}).Return(map[string]any{
+		"pagination": map[string]any{"after_cursor": "cursor123"},
+		"data":       []any{map[string]any{"id": float64(2)}, map[string]any{"id": float64(1)}},
+	}, nil).Once()
+
+	for e, err := range o.Events(EventsQuery{}) {
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), e.ID)
+		break
+	}
+
+	// The second page must not be requested once the consumer stops
+	mockClient.AssertNumberOfCalls(t, "ListEvents", 1)
+}
+
+func TestEventSIEMRecord(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := Event{
+		ID:               42,
+		CreatedAt:        &createdAt,
+		EventTypeID:      5,
+		EventType:        "USER_LOGGED_INTO_ONELOGIN",
+		UserID:           2,
+		UserName:         "alice",
+		IPAddr:           "192.0.2.1",
+		RiskScore:        10,
+		ErrorDescription: "denied",
+	}
+
+	r := e.SIEMRecord()
+	assert.Equal(t, "42", r.ID)
+	assert.Equal(t, createdAt, r.Time)
+	assert.Equal(t, int32(5), r.TypeID)
+	assert.Equal(t, "alice", r.UserName)
+	assert.Equal(t, "192.0.2.1", r.SourceIP)
+	assert.Equal(t, "denied", r.Message)
+
+	e.CustomMessage = "custom"
+	assert.Equal(t, "custom", e.SIEMRecord().Message)
+}
+
+func TestListEventsClientSideFilters(t *testing.T) {
+	mockClient := new(utils.MockClient)
+	o := &Onelogin{
+		client: mockClient,
+	}
+
+	query := EventsQuery{
+		AppIDs:  []int32{10},
+		IPNets:  []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+		RiskMin: 50,
+	}
+
+	mockClient.On("GetEventTypes", nil).Return(map[string]any{"data": []any{}}, nil)
+	// The client-side filters are not sent to the API
+	mockClient.On("ListEvents", &EventsQuery{Limit: strconv.Itoa(DefaultPageSize), AppIDs: query.AppIDs, IPNets: query.IPNets, RiskMin: 50}).Return(map[string]any{
+		"data": []any{
+			map[string]any{"id": float64(1), "app_id": float64(10), "ipaddr": "192.0.2.5", "risk_score": float64(80)},
+			map[string]any{"id": float64(2), "app_id": float64(11), "ipaddr": "192.0.2.5", "risk_score": float64(80)},
+			map[string]any{"id": float64(3), "app_id": float64(10), "ipaddr": "198.51.100.1", "risk_score": float64(80)},
+			map[string]any{"id": float64(4), "app_id": float64(10), "ipaddr": "192.0.2.6", "risk_score": float64(20)},
+			map[string]any{"id": float64(5), "app_id": float64(10), "ipaddr": "", "risk_score": float64(90)},
+			map[string]any{"id": float64(6), "app_id": float64(10), "ipaddr": "::ffff:192.0.2.7", "risk_score": float64(50)},
+		},
+	}, nil)
+
+	events, err := o.ListEvents(query)
+	assert.NoError(t, err)
+
+	var ids []uint64
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []uint64{1, 6}, ids)
+
+	path, err := utl.AddQueryToPath("api/1/events", &query)
+	assert.NoError(t, err)
+	assert.Equal(t, "api/1/events", path)
+}
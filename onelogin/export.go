@@ -0,0 +1,159 @@
+package onelogin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// ExportState is the checkpoint of an incremental event export.
+//
+// Each run exports the window [Since, Until]. Until is fixed when the window
+// starts and Cursor records the next page to fetch, so a run that dies
+// halfway resumes the same window where it stopped. When the window is
+// finished Since moves up to Until and the next run starts a new window.
+type ExportState struct {
+	Since  string `json:"since"`
+	Until  string `json:"until,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	// BoundaryIDs are the events already exported at the second of Since.
+	// The API's time filters have second precision, so these come back at
+	// the start of the next window and are skipped.
+	BoundaryIDs []uint64 `json:"boundary_ids,omitempty"`
+	// NextBoundaryIDs collects the events exported at the second of Until
+	// and become BoundaryIDs once the window is finished
+	NextBoundaryIDs []uint64 `json:"next_boundary_ids,omitempty"`
+}
+
+// LoadExportState reads the state file. It returns nil without an error if
+// the file does not exist yet.
+func LoadExportState(path string) (*ExportState, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state ExportState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file atomically so that a crash never leaves a
+// truncated checkpoint behind
+func (s *ExportState) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// DefaultExportLag is how far behind the current time an export window ends
+// by default, as OneLogin may store events a while after their created_at
+const DefaultExportLag = time.Hour
+
+// ExportEvents exports the events of the current window of state. fn is
+// called with each page, and checkpoint is called after fn succeeds so the
+// caller can persist state. The query's Since, Until and Cursor are
+// overridden by state. end is used as the upper bound of a new window; the
+// next window starts there, so end should lag behind the current time enough
+// for late events to have arrived (see DefaultExportLag). Nothing is exported
+// while end is not after the start of the window.
+//
+// A run killed after fn but before checkpoint repeats that one page on the
+// next run; every other event is exported exactly once.
+func (o *Onelogin) ExportEvents(query EventsQuery, state *ExportState, end time.Time, fn func([]Event) error, checkpoint func() error) error {
+	if state.Since == "" {
+		return fmt.Errorf("export state has no start time")
+	}
+	since, err := time.Parse(time.RFC3339, state.Since)
+	if err != nil {
+		return fmt.Errorf("invalid since in export state: %v", err)
+	}
+
+	if state.Until == "" {
+		end = end.UTC().Truncate(time.Second)
+		if !end.After(since) {
+			return nil
+		}
+		state.Until = end.Format(time.RFC3339)
+		state.Cursor = ""
+		state.NextBoundaryIDs = nil
+		if err := checkpoint(); err != nil {
+			return err
+		}
+	}
+
+	until, err := time.Parse(time.RFC3339, state.Until)
+	if err != nil {
+		return fmt.Errorf("invalid until in export state: %v", err)
+	}
+
+	query.Since = &state.Since
+	query.Until = &state.Until
+	query.Cursor = state.Cursor
+
+	err = o.eachEventPage(query, func(page []Event, nextCursor string) error {
+		events := make([]Event, 0, len(page))
+		for _, e := range page {
+			if e.CreatedAt != nil {
+				t := e.CreatedAt.UTC()
+				if t.Before(since) || !t.Before(until.Add(time.Second)) {
+					continue
+				}
+				if t.Truncate(time.Second).Equal(since) && slices.Contains(state.BoundaryIDs, e.ID) {
+					continue
+				}
+				if t.Truncate(time.Second).Equal(until) {
+					state.NextBoundaryIDs = append(state.NextBoundaryIDs, e.ID)
+				}
+			}
+			events = append(events, e)
+		}
+
+		if err := fn(events); err != nil {
+			return err
+		}
+
+		state.Cursor = nextCursor
+		return checkpoint()
+	})
+	if err != nil {
+		return err
+	}
+
+	// The window is complete; the next run starts where this one ended
+	state.Since = state.Until
+	state.Until = ""
+	state.Cursor = ""
+	state.BoundaryIDs = state.NextBoundaryIDs
+	state.NextBoundaryIDs = nil
+	return checkpoint()
+}
@@ -0,0 +1,196 @@
+package onelogin
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportStateSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.state")
+
+	state, err := LoadExportState(path)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	want := &ExportState{Since: "2026-10-01T00:00:00Z", Cursor: "abc", BoundaryIDs: []uint64{1, 2}}
+	require.NoError(t, want.Save(path))
+
+	got, err := LoadExportState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestExportEvents(t *testing.T) {
+	since := "2026-10-01T00:00:00Z"
+	until := "2026-10-02T00:00:00Z"
+	now := time.Date(2026, 10, 2, 0, 0, 0, 500, time.UTC)
+
+	eventTypes := map[string]any{"data": []any{}}
+	page := func(cursor any, ids map[int]string) map[string]any {
+		data := []any{}
+		for id, createdAt := range ids {
+			data = append(data, map[string]any{"id": float64(id), "created_at": createdAt})
+		}
+		return map[string]any{"pagination": map[string]any{"after_cursor": cursor}, "data": data}
+	}
+
+	t.Run("new window is exported page by page with a checkpoint after each page", func(t *testing.T) {
+		mockClient := new(utils.MockClient)
+		o := &Onelogin{client: mockClient}
+
+		mockClient.On("GetEventTypes", nil).Return(eventTypes, nil)
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit: strconv.Itoa(DefaultPageSize),
+			Since: &since,
+			Until: &until,
+		}).Return(page("next", map[int]string{
+			// at the upper boundary second: remembered for the next window
+			30: "2026-10-02T00:00:00.200Z",
+		}), nil).Once()
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit:  strconv.Itoa(DefaultPageSize),
+			Since:  &since,
+			Until:  &until,
+			Cursor: "next",
+		}).Return(page(nil, map[int]string{
+			// exported at the end of the previous window
+			10: "2026-10-01T00:00:00.100Z",
+		}), nil).Once()
+
+		state := &ExportState{Since: since, BoundaryIDs: []uint64{10}}
+		var exported []uint64
+		var checkpoints []ExportState
+
+		err := o.ExportEvents(EventsQuery{}, state, now, func(events []Event) error {
+			for _, e := range events {
+				exported = append(exported, e.ID)
+			}
+			return nil
+		}, func() error {
+			checkpoints = append(checkpoints, *state)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{30}, exported)
+		assert.Equal(t, []ExportState{
+			{Since: since, Until: until, BoundaryIDs: []uint64{10}},
+			{Since: since, Until: until, Cursor: "next", BoundaryIDs: []uint64{10}, NextBoundaryIDs: []uint64{30}},
+			{Since: since, Until: until, BoundaryIDs: []uint64{10}, NextBoundaryIDs: []uint64{30}},
+			{Since: until, BoundaryIDs: []uint64{30}},
+		}, checkpoints)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("interrupted window resumes from the saved cursor", func(t *testing.T) {
+		mockClient := new(utils.MockClient)
+		o := &Onelogin{client: mockClient}
+
+		mockClient.On("GetEventTypes", nil).Return(eventTypes, nil)
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit:  strconv.Itoa(DefaultPageSize),
+			Since:  &since,
+			Until:  &until,
+			Cursor: "next",
+		}).Return(page(nil, map[int]string{20: "2026-10-01T12:00:00Z"}), nil).Once()
+
+		state := &ExportState{Since: since, Until: until, Cursor: "next"}
+		var exported []uint64
+
+		err := o.ExportEvents(EventsQuery{}, state, now.Add(time.Hour), func(events []Event) error {
+			for _, e := range events {
+				exported = append(exported, e.ID)
+			}
+			return nil
+		}, func() error { return nil })
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{20}, exported)
+		assert.Equal(t, &ExportState{Since: until}, state)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("late event behind a completed window is exported by the next window", func(t *testing.T) {
+		mockClient := new(utils.MockClient)
+		o := &Onelogin{client: mockClient}
+		next := "2026-10-02T01:00:00Z"
+
+		mockClient.On("GetEventTypes", nil).Return(eventTypes, nil)
+		// The first run at 01:00 ends its window an hour earlier, before
+		// event 40 (created at 00:30) has been stored
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit: strconv.Itoa(DefaultPageSize),
+			Since: &since,
+			Until: &until,
+		}).Return(page(nil, map[int]string{20: "2026-10-01T12:00:00Z"}), nil).Once()
+		// By the next run event 40 has arrived and falls in the new window
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit: strconv.Itoa(DefaultPageSize),
+			Since: &until,
+			Until: &next,
+		}).Return(page(nil, map[int]string{40: "2026-10-02T00:30:00Z"}), nil).Once()
+
+		state := &ExportState{Since: since}
+		var exported []uint64
+		write := func(events []Event) error {
+			for _, e := range events {
+				exported = append(exported, e.ID)
+			}
+			return nil
+		}
+
+		first := time.Date(2026, 10, 2, 1, 0, 0, 0, time.UTC)
+		require.NoError(t, o.ExportEvents(EventsQuery{}, state, first.Add(-DefaultExportLag), write, func() error { return nil }))
+		assert.Equal(t, &ExportState{Since: until}, state)
+
+		second := first.Add(time.Hour)
+		require.NoError(t, o.ExportEvents(EventsQuery{}, state, second.Add(-DefaultExportLag), write, func() error { return nil }))
+		assert.Equal(t, []uint64{20, 40}, exported)
+		assert.Equal(t, &ExportState{Since: next}, state)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("no window is started before its end has passed the start", func(t *testing.T) {
+		mockClient := new(utils.MockClient)
+		o := &Onelogin{client: mockClient}
+
+		state := &ExportState{Since: since}
+		err := o.ExportEvents(EventsQuery{}, state, time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC), func(events []Event) error {
+			t.Fatal("no events should be exported")
+			return nil
+		}, func() error {
+			t.Fatal("no checkpoint should be saved")
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &ExportState{Since: since}, state)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("failed write does not advance the cursor", func(t *testing.T) {
+		mockClient := new(utils.MockClient)
+		o := &Onelogin{client: mockClient}
+
+		mockClient.On("GetEventTypes", nil).Return(eventTypes, nil)
+		mockClient.On("ListEvents", &EventsQuery{
+			Limit: strconv.Itoa(DefaultPageSize),
+			Since: &since,
+			Until: &until,
+		}).Return(page("next", map[int]string{20: "2026-10-01T12:00:00Z"}), nil).Once()
+
+		state := &ExportState{Since: since, Until: until}
+		err := o.ExportEvents(EventsQuery{}, state, now, func(events []Event) error {
+			return assert.AnError
+		}, func() error { return nil })
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, "", state.Cursor)
+	})
+}
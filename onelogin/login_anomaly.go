@@ -0,0 +1,299 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+)
+
+// DefaultLoginEventTypes are the event types read by the login anomaly report
+var DefaultLoginEventTypes = []string{
+	"USER_LOGGED_INTO_ONELOGIN",
+	"USER_FAILED_ONELOGIN_AUTHENTICATION",
+}
+
+// Kinds of login anomalies
+const (
+	LoginAnomalyNewIP            = "new_ip"
+	LoginAnomalyNewFingerprint   = "new_fingerprint"
+	LoginAnomalyNewCountry       = "new_country"
+	LoginAnomalyImpossibleTravel = "impossible_travel"
+	LoginAnomalyHighRisk         = "high_risk"
+	LoginAnomalyFailureBurst     = "failure_burst"
+)
+
+// LoginAnomalyOptions controls DetectLoginAnomalies
+type LoginAnomalyOptions struct {
+	// Since is the start of the reported window. Earlier events form the
+	// baseline of known IPs, fingerprints and countries and are not reported.
+	Since time.Time
+	// RiskThreshold flags logins with at least this risk score when positive
+	RiskThreshold int32
+	// FailureCount failed logins within FailureWindow are flagged as a burst
+	FailureCount  int
+	FailureWindow time.Duration
+	// MaxSpeedKmh is the fastest plausible travel between two logins
+	MaxSpeedKmh float64
+	// GeoIP resolves IP addresses to locations for the country and travel
+	// checks, which are skipped when it is nil
+	GeoIP utils.GeoIPDB
+}
+
+// LoginAnomaly is a suspicious login or group of logins
+type LoginAnomaly struct {
+	Kind      string    `json:"kind"`
+	Time      time.Time `json:"time"`
+	Detail    string    `json:"detail"`
+	IPAddr    string    `json:"ipaddr,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	RiskScore int32     `json:"risk_score,omitempty"`
+	EventIDs  []uint64  `json:"event_ids"`
+}
+
+// LoginUserReport is the login activity of one user in the reported window
+type LoginUserReport struct {
+	UserID    int32          `json:"user_id"`
+	UserName  string         `json:"user_name"`
+	Successes int            `json:"successes"`
+	Failures  int            `json:"failures"`
+	IPAddrs   []string       `json:"ipaddrs,omitempty"`
+	Anomalies []LoginAnomaly `json:"anomalies,omitempty"`
+}
+
+// isFailedLogin reports whether a login event is a failed attempt
+func isFailedLogin(e Event) bool {
+	name := strings.ToUpper(e.EventType)
+	return strings.Contains(name, "FAIL") || strings.Contains(name, "REJECT")
+}
+
+// loginState is what is known about a user's logins so far
+type loginState struct {
+	report       *LoginUserReport
+	ips          map[string]bool
+	fingerprints map[string]bool
+	countries    map[string]bool
+	failures     []Event
+	// last is the most recent successful login with a known location
+	last         *Event
+	lastLocation utils.GeoLocation
+}
+
+// DetectLoginAnomalies groups login events per user and flags, within the
+// window starting at opts.Since:
+//   - successful logins from an IP, browser fingerprint or country the user
+//     has not logged in from before
+//   - consecutive successful logins too far apart to travel between
+//   - logins with a high risk score
+//   - bursts of failed logins
+//
+// A user's first IP, fingerprint and country are taken as known when there is
+// no earlier login to compare with. Users are sorted by number of anomalies,
+// most first.
+func DetectLoginAnomalies(events []Event, opts LoginAnomalyOptions) []LoginUserReport {
+	events = slices.Clone(events)
+	SortEventsChronologically(events)
+
+	states := map[int32]*loginState{}
+	for _, e := range events {
+		if e.UserID == 0 || e.CreatedAt == nil {
+			continue
+		}
+		s, ok := states[e.UserID]
+		if !ok {
+			s = &loginState{
+				report:       &LoginUserReport{UserID: e.UserID},
+				ips:          map[string]bool{},
+				fingerprints: map[string]bool{},
+				countries:    map[string]bool{},
+			}
+			states[e.UserID] = s
+		}
+		s.process(e, opts, !e.CreatedAt.Before(opts.Since))
+	}
+
+	reports := []LoginUserReport{}
+	for _, s := range states {
+		r := s.report
+		if r.Successes+r.Failures == 0 {
+			// Only baseline activity
+			continue
+		}
+		reports = append(reports, *r)
+	}
+	slices.SortFunc(reports, func(a, b LoginUserReport) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Anomalies), len(a.Anomalies)),
+			cmp.Compare(a.UserName, b.UserName),
+			cmp.Compare(a.UserID, b.UserID),
+		)
+	})
+	return reports
+}
+
+func (s *loginState) process(e Event, opts LoginAnomalyOptions, report bool) {
+	r := s.report
+	if e.UserName != "" {
+		r.UserName = e.UserName
+	}
+
+	var location utils.GeoLocation
+	located := false
+	if opts.GeoIP != nil {
+		if addr, err := netip.ParseAddr(e.IPAddr); err == nil {
+			location, located = opts.GeoIP.Lookup(addr)
+		}
+	}
+
+	anomaly := func(kind, detail string) {
+		if report {
+			r.Anomalies = append(r.Anomalies, LoginAnomaly{
+				Kind:      kind,
+				Time:      e.CreatedAt.UTC(),
+				Detail:    detail,
+				IPAddr:    e.IPAddr,
+				Country:   location.Country,
+				RiskScore: e.RiskScore,
+				EventIDs:  []uint64{e.ID},
+			})
+		}
+	}
+
+	if report {
+		if isFailedLogin(e) {
+			r.Failures++
+		} else {
+			r.Successes++
+		}
+		if e.IPAddr != "" && !slices.Contains(r.IPAddrs, e.IPAddr) {
+			r.IPAddrs = append(r.IPAddrs, e.IPAddr)
+		}
+	}
+
+	if opts.RiskThreshold > 0 && e.RiskScore >= opts.RiskThreshold {
+		detail := fmt.Sprintf("risk score %d", e.RiskScore)
+		if e.RiskReasons != "" {
+			detail += ": " + e.RiskReasons
+		}
+		anomaly(LoginAnomalyHighRisk, detail)
+	}
+
+	if isFailedLogin(e) {
+		s.processFailure(e, opts, report)
+		return
+	}
+
+	if isNewValue(s.ips, e.IPAddr) {
+		anomaly(LoginAnomalyNewIP, "first login from "+e.IPAddr)
+	}
+	if isNewValue(s.fingerprints, e.BrowserFingerprint) {
+		anomaly(LoginAnomalyNewFingerprint, "first login with browser fingerprint "+e.BrowserFingerprint)
+	}
+	if located && isNewValue(s.countries, location.Country) {
+		anomaly(LoginAnomalyNewCountry, "first login from "+location.Country)
+	}
+
+	// Locations at 0,0 are treated as having no coordinates
+	if !located || (location.Latitude == 0 && location.Longitude == 0) {
+		return
+	}
+	if s.last != nil && opts.MaxSpeedKmh > 0 {
+		distance := utils.DistanceKm(s.lastLocation, location)
+		elapsed := e.CreatedAt.Sub(*s.last.CreatedAt)
+		if distance > 0 && distance/max(elapsed.Hours(), 1.0/3600) > opts.MaxSpeedKmh {
+			anomaly(LoginAnomalyImpossibleTravel, fmt.Sprintf("%.0f km from %s (%s) in %s",
+				distance, s.last.IPAddr, placeName(s.lastLocation), elapsed.Round(time.Second)))
+			if report {
+				a := &r.Anomalies[len(r.Anomalies)-1]
+				a.EventIDs = []uint64{s.last.ID, e.ID}
+			}
+		}
+	}
+	s.last = &e
+	s.lastLocation = location
+}
+
+func (s *loginState) processFailure(e Event, opts LoginAnomalyOptions, report bool) {
+	if opts.FailureCount <= 0 {
+		return
+	}
+	s.failures = slices.DeleteFunc(append(s.failures, e), func(f Event) bool {
+		return !f.CreatedAt.After(e.CreatedAt.Add(-opts.FailureWindow))
+	})
+	if len(s.failures) < opts.FailureCount {
+		return
+	}
+	burst := s.failures
+	// Start over so the same burst is reported once
+	s.failures = nil
+	if !report {
+		return
+	}
+
+	ids := make([]uint64, len(burst))
+	ips := []string{}
+	for i, f := range burst {
+		ids[i] = f.ID
+		if f.IPAddr != "" && !slices.Contains(ips, f.IPAddr) {
+			ips = append(ips, f.IPAddr)
+		}
+	}
+	s.report.Anomalies = append(s.report.Anomalies, LoginAnomaly{
+		Kind:     LoginAnomalyFailureBurst,
+		Time:     e.CreatedAt.UTC(),
+		Detail:   fmt.Sprintf("%d failed logins within %s from %s", len(burst), opts.FailureWindow, strings.Join(ips, ", ")),
+		IPAddr:   e.IPAddr,
+		EventIDs: ids,
+	})
+}
+
+// isNewValue records value as seen and reports whether it is new while
+// other values were already known. Empty values are ignored.
+func isNewValue(seen map[string]bool, value string) bool {
+	if value == "" || seen[value] {
+		return false
+	}
+	known := len(seen) > 0
+	seen[value] = true
+	return known
+}
+
+func placeName(loc utils.GeoLocation) string {
+	if loc.City != "" {
+		return loc.City + ", " + loc.Country
+	}
+	return loc.Country
+}
+
+// LoginAnomalyRows returns the report as table rows for CSV and XLSX output,
+// with one row per anomaly
+func LoginAnomalyRows(reports []LoginUserReport) [][]string {
+	rows := [][]string{{"user_id", "user_name", "successes", "failures", "kind", "time", "ipaddr", "country", "risk_score", "detail", "event_ids"}}
+	for _, r := range reports {
+		for _, a := range r.Anomalies {
+			ids := make([]string, len(a.EventIDs))
+			for i, id := range a.EventIDs {
+				ids[i] = strconv.FormatUint(id, 10)
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(int(r.UserID)),
+				r.UserName,
+				strconv.Itoa(r.Successes),
+				strconv.Itoa(r.Failures),
+				a.Kind,
+				a.Time.Format(time.RFC3339),
+				a.IPAddr,
+				a.Country,
+				strconv.Itoa(int(a.RiskScore)),
+				a.Detail,
+				strings.Join(ids, " "),
+			})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,168 @@
+package onelogin
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGeoIP maps single addresses to locations
+type testGeoIP map[string]utils.GeoLocation
+
+func (g testGeoIP) Lookup(addr netip.Addr) (utils.GeoLocation, bool) {
+	loc, ok := g[addr.String()]
+	return loc, ok
+}
+
+func TestDetectLoginAnomalies(t *testing.T) {
+	since := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	login := func(id uint64, at time.Time, user int32, ip, fingerprint string) Event {
+		return Event{
+			ID:                 id,
+			CreatedAt:          &at,
+			EventType:          "USER_LOGGED_INTO_ONELOGIN",
+			UserID:             user,
+			UserName:           "user" + string(rune('0'+user)),
+			IPAddr:             ip,
+			BrowserFingerprint: fingerprint,
+		}
+	}
+	failure := func(id uint64, at time.Time, user int32, ip string) Event {
+		e := login(id, at, user, ip, "")
+		e.EventType = "USER_FAILED_ONELOGIN_AUTHENTICATION"
+		return e
+	}
+
+	geoip := testGeoIP{
+		"203.0.113.1":  {Country: "JP", City: "Tokyo", Latitude: 35.68, Longitude: 139.69},
+		"203.0.113.2":  {Country: "JP", City: "Osaka", Latitude: 34.69, Longitude: 135.50},
+		"198.51.100.1": {Country: "US", City: "New York", Latitude: 40.71, Longitude: -74.01},
+	}
+	opts := LoginAnomalyOptions{
+		Since:         since,
+		RiskThreshold: 75,
+		FailureCount:  3,
+		FailureWindow: 10 * time.Minute,
+		MaxSpeedKmh:   900,
+		GeoIP:         geoip,
+	}
+
+	t.Run("baseline and new values", func(t *testing.T) {
+		events := []Event{
+			// Returned newest first, as the API does
+			login(3, since.Add(2*time.Hour), 1, "203.0.113.2", "fp-a"),
+			login(2, since.Add(time.Hour), 1, "203.0.113.1", "fp-b"),
+			login(1, since.Add(-24*time.Hour), 1, "203.0.113.1", "fp-a"),
+		}
+
+		reports := DetectLoginAnomalies(events, opts)
+		require.Len(t, reports, 1)
+		r := reports[0]
+		assert.Equal(t, int32(1), r.UserID)
+		assert.Equal(t, 2, r.Successes)
+		assert.Equal(t, []string{"203.0.113.1", "203.0.113.2"}, r.IPAddrs)
+		require.Len(t, r.Anomalies, 2)
+		assert.Equal(t, LoginAnomalyNewFingerprint, r.Anomalies[0].Kind)
+		assert.Equal(t, []uint64{2}, r.Anomalies[0].EventIDs)
+		assert.Equal(t, LoginAnomalyNewIP, r.Anomalies[1].Kind)
+		assert.Equal(t, "JP", r.Anomalies[1].Country)
+	})
+
+	t.Run("first login without baseline", func(t *testing.T) {
+		reports := DetectLoginAnomalies([]Event{login(1, since.Add(time.Hour), 1, "203.0.113.1", "fp-a")}, opts)
+		require.Len(t, reports, 1)
+		assert.Empty(t, reports[0].Anomalies)
+	})
+
+	t.Run("new country and impossible travel", func(t *testing.T) {
+		events := []Event{
+			login(1, since.Add(time.Hour), 1, "203.0.113.1", ""),
+			login(2, since.Add(3*time.Hour), 1, "198.51.100.1", ""),
+		}
+
+		reports := DetectLoginAnomalies(events, opts)
+		require.Len(t, reports, 1)
+		kinds := []string{}
+		for _, a := range reports[0].Anomalies {
+			kinds = append(kinds, a.Kind)
+		}
+		assert.Equal(t, []string{LoginAnomalyNewIP, LoginAnomalyNewCountry, LoginAnomalyImpossibleTravel}, kinds)
+		travel := reports[0].Anomalies[2]
+		assert.Equal(t, []uint64{1, 2}, travel.EventIDs)
+		assert.Contains(t, travel.Detail, "Tokyo, JP")
+	})
+
+	t.Run("plausible travel", func(t *testing.T) {
+		events := []Event{
+			login(1, since.Add(time.Hour), 1, "203.0.113.1", ""),
+			login(2, since.Add(5*time.Hour), 1, "203.0.113.2", ""),
+		}
+
+		reports := DetectLoginAnomalies(events, opts)
+		require.Len(t, reports, 1)
+		require.Len(t, reports[0].Anomalies, 1)
+		assert.Equal(t, LoginAnomalyNewIP, reports[0].Anomalies[0].Kind)
+	})
+
+	t.Run("high risk and failure burst", func(t *testing.T) {
+		risky := login(1, since.Add(time.Hour), 2, "192.0.2.1", "")
+		risky.RiskScore = 90
+		risky.RiskReasons = "new device"
+		events := []Event{
+			risky,
+			failure(2, since.Add(2*time.Hour), 2, "192.0.2.9"),
+			failure(3, since.Add(2*time.Hour+time.Minute), 2, "192.0.2.9"),
+			failure(4, since.Add(2*time.Hour+2*time.Minute), 2, "192.0.2.10"),
+			// Outside the window of the first failures
+			failure(5, since.Add(3*time.Hour), 2, "192.0.2.9"),
+		}
+
+		reports := DetectLoginAnomalies(events, opts)
+		require.Len(t, reports, 1)
+		r := reports[0]
+		assert.Equal(t, 1, r.Successes)
+		assert.Equal(t, 4, r.Failures)
+		require.Len(t, r.Anomalies, 2)
+		assert.Equal(t, LoginAnomalyHighRisk, r.Anomalies[0].Kind)
+		assert.Equal(t, "risk score 90: new device", r.Anomalies[0].Detail)
+		assert.Equal(t, LoginAnomalyFailureBurst, r.Anomalies[1].Kind)
+		assert.Equal(t, []uint64{2, 3, 4}, r.Anomalies[1].EventIDs)
+		assert.Contains(t, r.Anomalies[1].Detail, "192.0.2.9, 192.0.2.10")
+	})
+
+	t.Run("users sorted by anomalies", func(t *testing.T) {
+		risky := login(3, since.Add(time.Hour), 2, "192.0.2.1", "")
+		risky.RiskScore = 80
+		events := []Event{
+			login(1, since.Add(time.Hour), 1, "203.0.113.1", ""),
+			risky,
+			// Baseline only
+			login(2, since.Add(-time.Hour), 3, "203.0.113.1", ""),
+		}
+
+		reports := DetectLoginAnomalies(events, opts)
+		require.Len(t, reports, 2)
+		assert.Equal(t, int32(2), reports[0].UserID)
+		assert.Equal(t, int32(1), reports[1].UserID)
+	})
+}
+
+func TestLoginAnomalyRows(t *testing.T) {
+	at := time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC)
+	rows := LoginAnomalyRows([]LoginUserReport{
+		{UserID: 1, UserName: "alice", Successes: 2, Failures: 1, Anomalies: []LoginAnomaly{
+			{Kind: LoginAnomalyNewIP, Time: at, Detail: "first login from 192.0.2.1", IPAddr: "192.0.2.1", EventIDs: []uint64{10}},
+			{Kind: LoginAnomalyImpossibleTravel, Time: at, Country: "US", EventIDs: []uint64{10, 11}},
+		}},
+		{UserID: 2, UserName: "bob", Successes: 1},
+	})
+
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"user_id", "user_name", "successes", "failures", "kind", "time", "ipaddr", "country", "risk_score", "detail", "event_ids"}, rows[0])
+	assert.Equal(t, []string{"1", "alice", "2", "1", "new_ip", "2026-10-12T01:00:00Z", "192.0.2.1", "", "0", "first login from 192.0.2.1", "10"}, rows[1])
+	assert.Equal(t, "10 11", rows[2][10])
+}
@@ -0,0 +1,145 @@
+package onelogin
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimelineEntry is one event in a user's timeline
+type TimelineEntry struct {
+	Time       *time.Time `json:"time,omitempty"`
+	EventID    uint64     `json:"event_id"`
+	EventType  string     `json:"event_type"`
+	AppName    string     `json:"app_name,omitempty"`
+	IPAddr     string     `json:"ipaddr,omitempty"`
+	Resolution int32      `json:"resolution,omitempty"`
+	Actor      string     `json:"actor,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	Failure    bool       `json:"failure"`
+}
+
+// isTimelineFailure reports whether an event is something going wrong for
+// the user: an error, a failed or rejected attempt, or a lockout
+func isTimelineFailure(e Event) bool {
+	name := strings.ToUpper(e.EventType)
+	return isErrorEvent(e) || isFailedLogin(e) || strings.Contains(name, "DENIED") ||
+		(strings.Contains(name, "LOCKED") && !strings.Contains(name, "UNLOCKED"))
+}
+
+// BuildUserTimeline turns a user's events into timeline entries, oldest first
+func BuildUserTimeline(events iter.Seq2[Event, error]) ([]TimelineEntry, error) {
+	var collected []Event
+	for e, err := range events {
+		if err != nil {
+			return nil, err
+		}
+		collected = append(collected, e)
+	}
+	SortEventsChronologically(collected)
+
+	entries := make([]TimelineEntry, 0, len(collected))
+	for _, e := range collected {
+		entry := TimelineEntry{
+			Time:       e.CreatedAt,
+			EventID:    e.ID,
+			EventType:  cmp.Or(e.EventType, strconv.Itoa(int(e.EventTypeID))),
+			AppName:    e.AppName,
+			IPAddr:     e.IPAddr,
+			Resolution: e.Resolution,
+			Message:    cmp.Or(e.ErrorDescription, e.CustomMessage, e.Notes),
+			Failure:    isTimelineFailure(e),
+		}
+		// Only mention the actor when someone else acted on the user
+		if e.ActorUserID != 0 && e.ActorUserID != e.UserID {
+			entry.Actor = cmp.Or(e.ActorUserName, strconv.Itoa(int(e.ActorUserID)))
+		} else if e.ActorUserID == 0 && e.ActorSystem != "" {
+			entry.Actor = e.ActorSystem
+		}
+		if entry.Time != nil {
+			t := entry.Time.UTC()
+			entry.Time = &t
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ANSI escape sequences used to highlight failures on a terminal
+const (
+	timelineFailureColor = "\x1b[1;31m"
+	timelineColorReset   = "\x1b[0m"
+)
+
+// WriteTimeline writes the entries as a compact text timeline with a heading
+// per UTC day and one line per event. Failures are marked with "!" and shown
+// in red when color is true.
+func WriteTimeline(w io.Writer, entries []TimelineEntry, color bool) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No events")
+		return err
+	}
+
+	typeWidth := 0
+	for _, e := range entries {
+		typeWidth = max(typeWidth, len(e.EventType))
+	}
+
+	day := ""
+	failures := 0
+	for _, e := range entries {
+		clock := "--:--:--"
+		if e.Time != nil {
+			clock = e.Time.Format(time.TimeOnly)
+			if d := e.Time.Format(time.DateOnly); d != day {
+				if day != "" {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintln(w, d)
+				day = d
+			}
+		}
+
+		marker := " "
+		if e.Failure {
+			marker = "!"
+			failures++
+		}
+		line := fmt.Sprintf("  %s %s %-*s", clock, marker, typeWidth, e.EventType)
+
+		var details []string
+		if e.AppName != "" {
+			details = append(details, "app="+e.AppName)
+		}
+		if e.IPAddr != "" {
+			details = append(details, "ip="+e.IPAddr)
+		}
+		if e.Resolution != 0 {
+			details = append(details, "resolution="+strconv.Itoa(int(e.Resolution)))
+		}
+		if e.Actor != "" {
+			details = append(details, "by="+e.Actor)
+		}
+		if e.Message != "" {
+			details = append(details, strconv.Quote(e.Message))
+		}
+		if len(details) > 0 {
+			line += "  " + strings.Join(details, "  ")
+		}
+		line = strings.TrimRight(line, " ")
+
+		if e.Failure && color {
+			line = timelineFailureColor + line + timelineColorReset
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d event(s), %d failure(s)\n", len(entries), failures)
+	return err
+}
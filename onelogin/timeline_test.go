@@ -0,0 +1,91 @@
+package onelogin
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func timelineEvents() []Event {
+	at := func(day, hour, minute int) *time.Time {
+		t := time.Date(2026, 10, day, hour, minute, 0, 0, time.FixedZone("JST", 9*60*60))
+		return &t
+	}
+	return []Event{
+		{ID: 3, CreatedAt: at(2, 9, 5), UserID: 100, EventType: "USER_LOCKED", ActorSystem: "Policy"},
+		{ID: 1, CreatedAt: at(1, 18, 0), UserID: 100, EventType: "USER_LOGGED_INTO_ONELOGIN", IPAddr: "192.0.2.1"},
+		{
+			ID: 2, CreatedAt: at(2, 9, 0), UserID: 100, EventType: "USER_FAILED_ONELOGIN_AUTHENTICATION",
+			IPAddr: "192.0.2.1", AppName: "Slack", Resolution: 2, ErrorDescription: "Invalid password",
+		},
+		{ID: 4, CreatedAt: at(2, 10, 0), UserID: 100, EventType: "USER_UNLOCKED", ActorUserID: 7, ActorUserName: "Support Admin"},
+		{ID: 5, CreatedAt: at(2, 10, 1), UserID: 100, EventTypeID: 999, ActorUserID: 100},
+	}
+}
+
+func TestBuildUserTimeline(t *testing.T) {
+	entries, err := BuildUserTimeline(eventSeq(timelineEvents()))
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	ids := make([]uint64, len(entries))
+	failures := make([]bool, len(entries))
+	for i, e := range entries {
+		ids[i] = e.EventID
+		failures[i] = e.Failure
+	}
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, ids)
+	assert.Equal(t, []bool{false, true, true, false, false}, failures)
+
+	assert.Equal(t, time.UTC, entries[0].Time.Location())
+	assert.Equal(t, "Invalid password", entries[1].Message)
+	assert.Equal(t, "Policy", entries[2].Actor)
+	assert.Equal(t, "Support Admin", entries[3].Actor)
+	assert.Empty(t, entries[4].Actor)
+	assert.Equal(t, "999", entries[4].EventType)
+
+	t.Run("error", func(t *testing.T) {
+		var seq iter.Seq2[Event, error] = func(yield func(Event, error) bool) {
+			yield(Event{}, errors.New("api error"))
+		}
+		_, err := BuildUserTimeline(seq)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteTimeline(t *testing.T) {
+	entries, err := BuildUserTimeline(eventSeq(timelineEvents()))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTimeline(&buf, entries, false))
+	expected := `2026-10-01
+  09:00:00   USER_LOGGED_INTO_ONELOGIN            ip=192.0.2.1
+
+2026-10-02
+  00:00:00 ! USER_FAILED_ONELOGIN_AUTHENTICATION  app=Slack  ip=192.0.2.1  resolution=2  "Invalid password"
+  00:05:00 ! USER_LOCKED                          by=Policy
+  01:00:00   USER_UNLOCKED                        by=Support Admin
+  01:01:00   999
+
+5 event(s), 2 failure(s)
+`
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteTimeline(&buf, entries, true))
+	for line := range strings.Lines(buf.String()) {
+		colored := strings.HasPrefix(line, timelineFailureColor)
+		assert.Equal(t, strings.Contains(line, " ! "), colored, line)
+	}
+
+	buf.Reset()
+	require.NoError(t, WriteTimeline(&buf, nil, false))
+	assert.Equal(t, "No events\n", buf.String())
+}
@@ -3,6 +3,7 @@ package onelogin
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/pepabo/onecli/utils"
 )
@@ -19,6 +20,50 @@ func (o *Onelogin) GetUsers(query UserQuery) ([]User, error) {
 	}, DefaultPageSize)
 }
 
+// FindUserID returns the ID of the user FindUser finds. Only the ID is
+// cached, as it never changes and the rest of the user should not be kept
+// on disk.
+func (o *Onelogin) FindUserID(emailOrUsername string) (int32, error) {
+	return utils.Cached(o.cache, "user_id:"+strings.ToLower(emailOrUsername), func() (int32, error) {
+		user, err := o.FindUser(emailOrUsername)
+		return user.ID, err
+	})
+}
+
+// FindUser returns the user with the given email address, or the given
+// username if it contains no @. It is an error unless exactly one user matches.
+func (o *Onelogin) FindUser(emailOrUsername string) (User, error) {
+	query := UserQuery{}
+	isEmail := strings.Contains(emailOrUsername, "@")
+	if isEmail {
+		query.Email = &emailOrUsername
+	} else {
+		query.Username = &emailOrUsername
+	}
+
+	users, err := o.GetUsers(query)
+	if err != nil {
+		return User{}, err
+	}
+
+	// The API also matches wildcards, so keep only exact matches
+	var matched []User
+	for _, u := range users {
+		if (isEmail && strings.EqualFold(u.Email, emailOrUsername)) || (!isEmail && strings.EqualFold(u.Username, emailOrUsername)) {
+			matched = append(matched, u)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return User{}, fmt.Errorf("user %q not found", emailOrUsername)
+	case 1:
+		return matched[0], nil
+	default:
+		return User{}, fmt.Errorf("%d users match %q", len(matched), emailOrUsername)
+	}
+}
+
 // UpdateUser updates a user in Onelogin
 func (o *Onelogin) UpdateUser(userID int, user User) error {
 	_, err := o.client.UpdateUser(userID, user)
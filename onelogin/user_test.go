@@ -1,7 +1,11 @@
 package onelogin
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
 	"github.com/pepabo/onecli/utils"
@@ -334,3 +338,109 @@ func TestSetPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestFindUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedQuery *models.UserQuery
+		mockResponse  []any
+		expectedID    int32
+		expectedError string
+	}{
+		{
+			name:          "by email",
+			input:         "Alice@example.com",
+			expectedQuery: &models.UserQuery{Page: "1", Email: func() *string { v := "Alice@example.com"; return &v }()},
+			mockResponse: []any{
+				map[string]any{"id": float64(1), "email": "alice@example.com.au"},
+				map[string]any{"id": float64(2), "email": "alice@example.com"},
+			},
+			expectedID: 2,
+		},
+		{
+			name:          "by username",
+			input:         "bob",
+			expectedQuery: &models.UserQuery{Page: "1", Username: func() *string { v := "bob"; return &v }()},
+			mockResponse:  []any{map[string]any{"id": float64(3), "username": "bob"}},
+			expectedID:    3,
+		},
+		{
+			name:          "not found",
+			input:         "carol",
+			expectedQuery: &models.UserQuery{Page: "1", Username: func() *string { v := "carol"; return &v }()},
+			mockResponse:  []any{map[string]any{"id": float64(4), "username": "carol2"}},
+			expectedError: `user "carol" not found`,
+		},
+		{
+			name:          "ambiguous",
+			input:         "dave",
+			expectedQuery: &models.UserQuery{Page: "1", Username: func() *string { v := "dave"; return &v }()},
+			mockResponse: []any{
+				map[string]any{"id": float64(5), "username": "dave"},
+				map[string]any{"id": float64(6), "username": "Dave"},
+			},
+			expectedError: `2 users match "dave"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(utils.MockClient)
+			o := &Onelogin{
+				client: mockClient,
+			}
+			mockClient.On("GetUsers", tt.expectedQuery).Return(tt.mockResponse, nil)
+
+			user, err := o.FindUser(tt.input)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, user.ID)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFindUserIDDiskCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	cache := utils.NewCache(cacheDir, time.Hour)
+	email := "alice@example.com"
+
+	mockClient := new(utils.MockClient)
+	mockClient.On("GetUsers", &models.UserQuery{Page: "1", Email: &email}).Return([]any{
+		map[string]any{"id": float64(2), "email": email, "firstname": "Alice", "status": float64(1)},
+	}, nil).Once()
+	first := &Onelogin{client: mockClient}
+	first.SetCache(cache)
+
+	id, err := first.FindUserID(email)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), id)
+	mockClient.AssertExpectations(t)
+
+	// A later process reads the ID from disk without calling the API
+	second := &Onelogin{client: new(utils.MockClient)}
+	second.SetCache(cache)
+	id, err = second.FindUserID("Alice@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), id)
+
+	// Nothing but the ID is written to the cache
+	err = filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		assert.NotContains(t, string(b), "Alice")
+		assert.NotContains(t, string(b), "status")
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -12,6 +12,11 @@ import (
 	utl "github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/utilities"
 )
 
+// ConnectorsPath is the connectors endpoint. onelogin-go-sdk has
+// ListConnectors, but it only fetches the first page, so the endpoint is
+// called directly with limit and page.
+const ConnectorsPath = "api/2/connectors"
+
 type OneloginSDK struct {
 	sdk *o.OneloginSDK
 }
@@ -113,6 +118,30 @@ func (s *OneloginSDK) GetApps(query models.Queryable) (any, error) {
 	return s.sdk.GetApps(query)
 }
 
+func (s *OneloginSDK) GetAppByID(appID int) (any, error) {
+	return s.sdk.GetAppByID(appID, nil)
+}
+
+// UpdateApp updates an app with an arbitrary body. The SDK's UpdateApp only
+// accepts models.App, whose omitempty bool fields cannot express false.
+func (s *OneloginSDK) UpdateApp(appID int, requestBody any) (any, error) {
+	p, err := utl.BuildAPIPath(o.AppPath, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.sdk.Client.Put(&p, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return utl.CheckHTTPResponse(r)
+}
+
+func (s *OneloginSDK) DeleteAppParameter(appID, parameterID int) (any, error) {
+	return s.sdk.DeleteAppParameter(appID, parameterID)
+}
+
 // Since GetAppUsers does not support pagination, we need to create a wrapper to support pagination.
 // This wrapper will become unnecessary once onelogin-go-sdk supports it.
 func (s *OneloginSDK) GetAppUsers(appID int, query models.Queryable) (any, error) {
@@ -124,10 +153,47 @@ func (s *OneloginSDK) GetAppUsers(appID int, query models.Queryable) (any, error
 	return s.get(p, query)
 }
 
+func (s *OneloginSDK) GetAppRules(appID int, query models.Queryable) (any, error) {
+	return s.sdk.GetAppRules(appID, query)
+}
+
+func (s *OneloginSDK) GetAppRuleByID(appID, ruleID int) (any, error) {
+	return s.sdk.GetAppRuleByID(appID, ruleID, nil)
+}
+
+func (s *OneloginSDK) CreateAppRule(appID int, rule models.AppRule) (any, error) {
+	return s.sdk.CreateAppRule(appID, rule)
+}
+
+func (s *OneloginSDK) DeleteAppRule(appID, ruleID int) (any, error) {
+	return s.sdk.DeleteAppRule(appID, ruleID, nil)
+}
+
+func (s *OneloginSDK) SortAppRules(appID int, ruleIDs []int) (any, error) {
+	return s.sdk.BulkSortAppRules(appID, ruleIDs)
+}
+
+func (s *OneloginSDK) GetRoles(query models.Queryable) (any, error) {
+	return s.sdk.GetRoles(query)
+}
+
+func (s *OneloginSDK) GetConnectors(query models.Queryable) (any, error) {
+	p, err := utl.BuildAPIPath(ConnectorsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.get(p, query)
+}
+
 func (s *OneloginSDK) ListEvents(query models.Queryable) (any, error) {
 	return s.sdk.ListEvents(query)
 }
 
+func (s *OneloginSDK) GetEventByID(eventID int) (any, error) {
+	return s.sdk.GetEvents(eventID, nil)
+}
+
 func (s *OneloginSDK) GetEventTypes(query models.Queryable) (any, error) {
 	return s.sdk.GetEventTypes(query)
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL はキャッシュの既定の有効期間です
+const DefaultCacheTTL = 24 * time.Hour
+
+// Cache はイベントタイプや名前からIDへの対応のような参照データをディスクに保存します
+// 期限切れや壊れたエントリは存在しないものとして扱います
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// CacheDir はキャッシュを保存するディレクトリを返します
+// Linux では $XDG_CACHE_HOME/onecli (未設定なら ~/.cache/onecli) です
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "onecli"), nil
+}
+
+// NewCache は dir にエントリを保存するキャッシュを作成します
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Get はキーに対応する有効なエントリを v に読み込み、見つかったかどうかを返します
+func (c *Cache) Get(key string, v any) bool {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return false
+	}
+	if c.now().Sub(entry.StoredAt) > c.ttl {
+		return false
+	}
+	return json.Unmarshal(entry.Data, v) == nil
+}
+
+// Set は v をキーに対応するエントリとして保存します
+func (c *Cache) Set(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(cacheEntry{StoredAt: c.now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	// 書き込み途中のファイルを読まないよう一時ファイルから置き換える
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Clear はキャッシュのエントリをすべて削除します
+func (c *Cache) Clear() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (c *Cache) path(key string) string {
+	// キーをエスケープしてファイル名に使う
+	return filepath.Join(c.dir, url.QueryEscape(key)+".json")
+}
+
+// Cached はキャッシュにエントリがあればそれを返し、なければ fetch の結果を保存して返します
+// cache が nil の場合は常に fetch を呼び出します
+// キャッシュへの保存に失敗しても fetch の結果は返します
+func Cached[T any](cache *Cache, key string, fetch func() (T, error)) (T, error) {
+	if cache != nil {
+		var v T
+		if cache.Get(key, &v) {
+			return v, nil
+		}
+	}
+
+	v, err := fetch()
+	if err != nil || cache == nil {
+		return v, err
+	}
+	_ = cache.Set(key, v)
+	return v, nil
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "example")
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	cache := NewCache(dir, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	var got []string
+	assert.False(t, cache.Get("user:alice@example.com", &got))
+
+	require.NoError(t, cache.Set("user:alice@example.com", []string{"a", "b"}))
+	assert.True(t, cache.Get("user:alice@example.com", &got))
+	assert.Equal(t, []string{"a", "b"}, got)
+
+	// Keys that only differ in escaped characters do not collide
+	assert.False(t, cache.Get("user:alice_example.com", &got))
+
+	now = now.Add(2 * time.Hour)
+	assert.False(t, cache.Get("user:alice@example.com", &got), "expired entry")
+
+	require.NoError(t, cache.Clear())
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, cache.Clear())
+}
+
+func TestCacheCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir, time.Hour)
+	require.NoError(t, os.WriteFile(cache.path("key"), []byte("{broken"), 0o600))
+
+	var got int
+	assert.False(t, cache.Get("key", &got))
+}
+
+func TestCached(t *testing.T) {
+	cache := NewCache(t.TempDir(), time.Hour)
+	calls := 0
+	fetch := func() (int, error) {
+		calls++
+		return 42, nil
+	}
+
+	for range 2 {
+		got, err := Cached(cache, "answer", fetch)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, got)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Without a cache every call fetches
+	_, _ = Cached(nil, "answer", fetch)
+	assert.Equal(t, 2, calls)
+
+	// Errors are not cached
+	_, err := Cached(cache, "error", func() (int, error) { return 0, assert.AnError })
+	assert.ErrorIs(t, err, assert.AnError)
+	var got int
+	assert.False(t, cache.Get("error", &got))
+}
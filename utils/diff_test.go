@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "正常系: 差分なし",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "正常系: 行の変更",
+			before: "label: Dept\nmapping: title\n",
+			after:  "label: Dept\nmapping: department\n",
+			want:   "  label: Dept\n- mapping: title\n+ mapping: department\n",
+		},
+		{
+			name:   "正常系: 新規追加",
+			before: "",
+			after:  "a\nb\n",
+			want:   "+ a\n+ b\n",
+		},
+		{
+			name:   "正常系: 削除",
+			before: "a\nb\n",
+			after:  "",
+			want:   "- a\n- b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.before, tt.after))
+		})
+	}
+}
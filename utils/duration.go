@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var dayWeekPattern = regexp.MustCompile(`(\d+)([dw])`)
+
+// ParseDuration は time.ParseDuration に日(d)と週(w)の単位を加えたものです
+// "60d", "2w", "1d12h" のような指定を受け付けます
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration: empty string")
+	}
+
+	var total time.Duration
+	rest := dayWeekPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := dayWeekPattern.FindStringSubmatch(m)
+		n, _ := strconv.Atoi(parts[1])
+		day := 24 * time.Hour
+		if parts[2] == "w" {
+			day *= 7
+		}
+		total += time.Duration(n) * day
+		return ""
+	})
+
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		total += d
+	}
+
+	return total, nil
+}
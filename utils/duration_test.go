@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "正常系: 日数", input: "60d", want: 60 * 24 * time.Hour},
+		{name: "正常系: 週数", input: "2w", want: 14 * 24 * time.Hour},
+		{name: "正常系: 日と時間の組み合わせ", input: "1d12h", want: 36 * time.Hour},
+		{name: "正常系: 標準形式", input: "15m", want: 15 * time.Minute},
+		{name: "異常系: 空文字列", input: "", wantErr: true},
+		{name: "異常系: 不正な単位", input: "3y", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
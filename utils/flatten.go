@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// FlattenRows は1件のデータを「項目名, 値」の2列の表に変換します
+// 入れ子のオブジェクトは "user.email" のようにドットで、配列は "roles.0" のように添字でつなげます
+// 1件の詳細をCSVやXLSXで出力するために使います
+func FlattenRows(data any) ([][]string, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{{"field", "value"}}
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		join := func(key string) string {
+			if prefix == "" {
+				return key
+			}
+			return prefix + "." + key
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				walk(join(k), v[k])
+			}
+		case []any:
+			for i, item := range v {
+				walk(join(fmt.Sprint(i)), item)
+			}
+		case nil:
+			rows = append(rows, []string{prefix, ""})
+		default:
+			rows = append(rows, []string{prefix, fmt.Sprint(v)})
+		}
+	}
+	walk("", v)
+	return rows, nil
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenRows(t *testing.T) {
+	type user struct {
+		ID    int64  `json:"id"`
+		Email string `json:"email"`
+	}
+	data := struct {
+		ID      uint64   `json:"id"`
+		Type    string   `json:"event_type"`
+		Score   float64  `json:"score"`
+		User    *user    `json:"user,omitempty"`
+		Actor   *user    `json:"actor"`
+		Reasons []string `json:"reasons"`
+	}{
+		ID:      9007199254740993,
+		Type:    "LOGIN",
+		Score:   0.5,
+		User:    &user{ID: 1, Email: "alice@example.com"},
+		Reasons: []string{"new ip", "new device"},
+	}
+
+	rows, err := FlattenRows(data)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"field", "value"},
+		{"actor", ""},
+		{"event_type", "LOGIN"},
+		{"id", "9007199254740993"},
+		{"reasons.0", "new ip"},
+		{"reasons.1", "new device"},
+		{"score", "0.5"},
+		{"user.email", "alice@example.com"},
+		{"user.id", "1"},
+	}, rows)
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GeoLocation はIPアドレスの地理情報です
+type GeoLocation struct {
+	Country   string  `json:"country,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GeoIPDB はIPアドレスから地理情報を引くデータベースです
+// オフラインで使えるファイル形式ごとに実装を差し替えられます
+type GeoIPDB interface {
+	Lookup(addr netip.Addr) (GeoLocation, bool)
+}
+
+// GeoIPTable はネットワーク(CIDR)ごとの地理情報をメモリに持つ GeoIPDB です
+// 重なるネットワークがある場合は最も長いプレフィックスが優先されます
+type GeoIPTable struct {
+	networks map[netip.Prefix]GeoLocation
+	// bits は登録されているプレフィックス長の一覧で、長い順に並んでいます
+	bits []int
+}
+
+// LoadGeoIPCSV は GeoIP の CSV ファイルを読み込みます
+func LoadGeoIPCSV(file string) (*GeoIPTable, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	table, err := ParseGeoIPCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GeoIP file %s: %v", file, err)
+	}
+	return table, nil
+}
+
+// ParseGeoIPCSV は次の列を持つ CSV を読み込みます
+//
+//	network,country,city,latitude,longitude
+//
+// 1行目はヘッダで、列の順序はヘッダで判断します。network 以外の列は省略できます
+// "#" で始まる行はコメントとして無視します
+func ParseGeoIPCSV(r io.Reader) (*GeoIPTable, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %v", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["network"]; !ok {
+		return nil, fmt.Errorf("missing network column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	table := &GeoIPTable{networks: map[netip.Prefix]GeoLocation{}}
+	seenBits := map[int]bool{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		prefix, err := netip.ParsePrefix(field(record, "network"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		prefix = prefix.Masked()
+
+		loc := GeoLocation{
+			Country: field(record, "country"),
+			City:    field(record, "city"),
+		}
+		if loc.Latitude, err = parseCoordinate(field(record, "latitude")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude: %v", line, err)
+		}
+		if loc.Longitude, err = parseCoordinate(field(record, "longitude")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude: %v", line, err)
+		}
+
+		table.networks[prefix] = loc
+		if !seenBits[prefix.Bits()] {
+			seenBits[prefix.Bits()] = true
+			table.bits = append(table.bits, prefix.Bits())
+		}
+	}
+	slices.SortFunc(table.bits, func(a, b int) int { return cmp.Compare(b, a) })
+	return table, nil
+}
+
+// parseCoordinate は緯度・経度を解釈します。空の場合は0を返します
+func parseCoordinate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// Lookup はアドレスを含む最も長いプレフィックスのネットワークの地理情報を返します
+func (t *GeoIPTable) Lookup(addr netip.Addr) (GeoLocation, bool) {
+	addr = addr.Unmap()
+	for _, bits := range t.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if loc, ok := t.networks[prefix]; ok {
+			return loc, true
+		}
+	}
+	return GeoLocation{}, false
+}
+
+// DistanceKm は2地点間の大円距離をキロメートルで返します
+func DistanceKm(a, b GeoLocation) float64 {
+	const earthRadiusKm = 6371.0
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := rad(b.Latitude - a.Latitude)
+	dLon := rad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(a.Latitude))*math.Cos(rad(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGeoIPCSV = `# test data
+network,country,city,latitude,longitude
+203.0.113.0/24,JP,Tokyo,35.68,139.69
+203.0.113.128/25,JP,Osaka,34.69,135.50
+198.51.100.0/24,US,,40.71,-74.01
+2001:db8::/32,DE,Berlin,52.52,13.40
+`
+
+func TestParseGeoIPCSV(t *testing.T) {
+	table, err := ParseGeoIPCSV(strings.NewReader(testGeoIPCSV))
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr     string
+		expected GeoLocation
+		found    bool
+	}{
+		{addr: "203.0.113.5", expected: GeoLocation{Country: "JP", City: "Tokyo", Latitude: 35.68, Longitude: 139.69}, found: true},
+		{addr: "203.0.113.200", expected: GeoLocation{Country: "JP", City: "Osaka", Latitude: 34.69, Longitude: 135.50}, found: true},
+		{addr: "::ffff:198.51.100.1", expected: GeoLocation{Country: "US", Latitude: 40.71, Longitude: -74.01}, found: true},
+		{addr: "2001:db8::1", expected: GeoLocation{Country: "DE", City: "Berlin", Latitude: 52.52, Longitude: 13.40}, found: true},
+		{addr: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			loc, ok := table.Lookup(netip.MustParseAddr(tt.addr))
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.expected, loc)
+		})
+	}
+}
+
+func TestParseGeoIPCSVInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "missing network column", data: "country\nJP\n"},
+		{name: "invalid network", data: "network,country\n203.0.113.0,JP\n"},
+		{name: "invalid latitude", data: "network,latitude\n203.0.113.0/24,north\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGeoIPCSV(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadGeoIPCSV(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(file, []byte(testGeoIPCSV), 0o600))
+
+	table, err := LoadGeoIPCSV(file)
+	require.NoError(t, err)
+	loc, ok := table.Lookup(netip.MustParseAddr("198.51.100.9"))
+	assert.True(t, ok)
+	assert.Equal(t, "US", loc.Country)
+
+	_, err = LoadGeoIPCSV(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestDistanceKm(t *testing.T) {
+	tokyo := GeoLocation{Latitude: 35.68, Longitude: 139.69}
+	newYork := GeoLocation{Latitude: 40.71, Longitude: -74.01}
+
+	assert.Equal(t, 0.0, DistanceKm(tokyo, tokyo))
+	assert.InDelta(t, 10850, DistanceKm(tokyo, newYork), 50)
+	assert.InDelta(t, DistanceKm(tokyo, newYork), DistanceKm(newYork, tokyo), 1e-9)
+}
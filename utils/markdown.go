@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const OutputFormatMarkdown OutputFormat = "markdown"
+
+// markdownEscaper は表のセルの中で意味を持つ文字をエスケープします
+var markdownEscaper = strings.NewReplacer(
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// WriteMarkdownTable は先頭行をヘッダーとする表を GitHub Flavored Markdown の表として書き込みます
+func WriteMarkdownTable(rows [][]string, writer io.Writer) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	writeRow := func(cells []string) error {
+		escaped := make([]string, width)
+		for i := range width {
+			if i < len(cells) {
+				escaped[i] = markdownEscaper.Replace(cells[i])
+			}
+		}
+		_, err := fmt.Fprintf(writer, "| %s |\n", strings.Join(escaped, " | "))
+		return err
+	}
+
+	if err := writeRow(rows[0]); err != nil {
+		return err
+	}
+	separator := make([]string, width)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if _, err := fmt.Fprintf(writer, "| %s |\n", strings.Join(separator, " | ")); err != nil {
+		return err
+	}
+	for _, row := range rows[1:] {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
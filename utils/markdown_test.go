@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdownTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     [][]string
+		expected string
+	}{
+		{name: "empty", rows: nil, expected: ""},
+		{
+			name: "header and rows",
+			rows: [][]string{{"name", "count"}, {"alice", "3"}, {"bob", "1"}},
+			expected: "| name | count |\n" +
+				"| --- | --- |\n" +
+				"| alice | 3 |\n" +
+				"| bob | 1 |\n",
+		},
+		{
+			name: "escapes pipes and newlines",
+			rows: [][]string{{"detail"}, {"a|b\nc"}},
+			expected: "| detail |\n" +
+				"| --- |\n" +
+				"| a\\|b<br>c |\n",
+		},
+		{
+			name: "short rows are padded",
+			rows: [][]string{{"a", "b"}, {"1"}},
+			expected: "| a | b |\n" +
+				"| --- | --- |\n" +
+				"| 1 |  |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteMarkdownTable(tt.rows, &buf))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestPrintOutputMarkdown(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var buf bytes.Buffer
+	require.NoError(t, PrintOutput([]item{{1, "a"}, {2, "b"}}, OutputFormatMarkdown, &buf))
+	assert.Equal(t, "| ID | Name |\n| --- | --- |\n| 1 | a |\n| 2 | b |\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, PrintTable([][]string{{"x"}, {"1"}}, OutputFormatMarkdown, &buf))
+	assert.Equal(t, "| x |\n| --- |\n| 1 |\n", buf.String())
+}
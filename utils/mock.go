@@ -46,18 +46,84 @@ func (m *MockClient) GetApps(query models.Queryable) (any, error) {
 	return args.Get(0), args.Error(1)
 }
 
+// GetAppByID mocks the GetAppByID method
+func (m *MockClient) GetAppByID(appID int) (any, error) {
+	args := m.Called(appID)
+	return args.Get(0), args.Error(1)
+}
+
+// UpdateApp mocks the UpdateApp method
+func (m *MockClient) UpdateApp(appID int, requestBody any) (any, error) {
+	args := m.Called(appID, requestBody)
+	return args.Get(0), args.Error(1)
+}
+
+// DeleteAppParameter mocks the DeleteAppParameter method
+func (m *MockClient) DeleteAppParameter(appID, parameterID int) (any, error) {
+	args := m.Called(appID, parameterID)
+	return args.Get(0), args.Error(1)
+}
+
 // GetAppUsers mocks the GetAppUsers method
 func (m *MockClient) GetAppUsers(appID int, query models.Queryable) (any, error) {
 	args := m.Called(appID, query)
 	return args.Get(0), args.Error(1)
 }
 
+// GetAppRules mocks the GetAppRules method
+func (m *MockClient) GetAppRules(appID int, query models.Queryable) (any, error) {
+	args := m.Called(appID, query)
+	return args.Get(0), args.Error(1)
+}
+
+// GetAppRuleByID mocks the GetAppRuleByID method
+func (m *MockClient) GetAppRuleByID(appID, ruleID int) (any, error) {
+	args := m.Called(appID, ruleID)
+	return args.Get(0), args.Error(1)
+}
+
+// CreateAppRule mocks the CreateAppRule method
+func (m *MockClient) CreateAppRule(appID int, rule models.AppRule) (any, error) {
+	args := m.Called(appID, rule)
+	return args.Get(0), args.Error(1)
+}
+
+// DeleteAppRule mocks the DeleteAppRule method
+func (m *MockClient) DeleteAppRule(appID, ruleID int) (any, error) {
+	args := m.Called(appID, ruleID)
+	return args.Get(0), args.Error(1)
+}
+
+// SortAppRules mocks the SortAppRules method
+func (m *MockClient) SortAppRules(appID int, ruleIDs []int) (any, error) {
+	args := m.Called(appID, ruleIDs)
+	return args.Get(0), args.Error(1)
+}
+
+// GetRoles mocks the GetRoles method
+func (m *MockClient) GetRoles(query models.Queryable) (any, error) {
+	args := m.Called(query)
+	return args.Get(0), args.Error(1)
+}
+
+// GetConnectors mocks the GetConnectors method
+func (m *MockClient) GetConnectors(query models.Queryable) (any, error) {
+	args := m.Called(query)
+	return args.Get(0), args.Error(1)
+}
+
 // ListEvents mocks the ListEvents method
 func (m *MockClient) ListEvents(query models.Queryable) (any, error) {
 	args := m.Called(query)
 	return args.Get(0), args.Error(1)
 }
 
+// GetEventByID mocks the GetEventByID method
+func (m *MockClient) GetEventByID(eventID int) (any, error) {
+	args := m.Called(eventID)
+	return args.Get(0), args.Error(1)
+}
+
 // GetEventTypes mocks the GetEventTypes method
 func (m *MockClient) GetEventTypes(query models.Queryable) (any, error) {
 	args := m.Called(query)
@@ -17,9 +17,11 @@ import (
 type OutputFormat string
 
 const (
-	OutputFormatYAML OutputFormat = "yaml"
-	OutputFormatJSON OutputFormat = "json"
-	OutputFormatCSV  OutputFormat = "csv"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+	OutputFormatCSV    OutputFormat = "csv"
+	OutputFormatXLSX   OutputFormat = "xlsx"
 )
 
 // PrintOutput は指定された形式でデータを出力します
@@ -35,28 +37,104 @@ func PrintOutput(data any, format OutputFormat, writer io.Writer) error {
 		encoder.SetIndent("", "  ")
 		encoder.SetEscapeHTML(false)
 		return encoder.Encode(data)
+	case OutputFormatNDJSON:
+		return encodeNDJSON(data, writer)
+	case OutputFormatCEF, OutputFormatLEEF, OutputFormatSyslog:
+		return encodeSIEM(data, format, writer)
 	case OutputFormatYAML:
 		return yaml.NewEncoder(writer).Encode(data)
 	case OutputFormatCSV:
 		return encodeCSV(data, writer)
+	case OutputFormatXLSX:
+		rows, err := tableRows(data)
+		if err != nil {
+			return err
+		}
+		return encodeXLSX(rows, "Sheet1", writer)
+	case OutputFormatMarkdown:
+		rows, err := tableRows(data)
+		if err != nil {
+			return err
+		}
+		return WriteMarkdownTable(rows, writer)
 	default:
 		return yaml.NewEncoder(writer).Encode(data)
 	}
 }
 
+// PrintTable は先頭行をヘッダーとする表形式のデータを出力します
+// 列が動的に決まるデータ向けで、csv と xlsx と markdown のみに対応します
+func PrintTable(rows [][]string, format OutputFormat, writer io.Writer) error {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	switch format {
+	case OutputFormatCSV:
+		return writeCSVRows(rows, writer)
+	case OutputFormatXLSX:
+		return encodeXLSX(rows, "Sheet1", writer)
+	case OutputFormatMarkdown:
+		return WriteMarkdownTable(rows, writer)
+	default:
+		return fmt.Errorf("unsupported output format for table data: %s (use csv, xlsx or markdown)", format)
+	}
+}
+
+// encodeNDJSON はデータを1行1件のJSONとしてエンコードします
+// スライスの場合は要素ごとに1行、それ以外は1行で出力します
+func encodeNDJSON(data any, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Slice {
+		return encoder.Encode(data)
+	}
+	for i := 0; i < val.Len(); i++ {
+		if err := encoder.Encode(val.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // encodeCSV はデータをCSV形式でエンコードします
 func encodeCSV(data any, writer io.Writer) error {
+	rows, err := tableRows(data)
+	if err != nil {
+		return err
+	}
+	return writeCSVRows(rows, writer)
+}
+
+// writeCSVRows は行データをCSVとして書き込みます
+func writeCSVRows(rows [][]string, writer io.Writer) error {
 	csvWriter := csv.NewWriter(writer)
 	defer csvWriter.Flush()
 
+	for i, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			if i == 0 {
+				return fmt.Errorf("error writing CSV headers: %v", err)
+			}
+			return fmt.Errorf("error writing CSV row %d: %v", i-1, err)
+		}
+	}
+
+	return nil
+}
+
+// tableRows は構造体のスライスをヘッダー行とデータ行に変換します
+func tableRows(data any) ([][]string, error) {
 	// データがスライスでない場合はエラー
 	val := reflect.ValueOf(data)
 	if val.Kind() != reflect.Slice {
-		return fmt.Errorf("CSV output requires slice data, got %v", val.Kind())
+		return nil, fmt.Errorf("table output (csv, xlsx, markdown) requires slice data, got %v", val.Kind())
 	}
 
 	if val.Len() == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// 最初の要素からヘッダーを生成
@@ -66,7 +144,7 @@ func encodeCSV(data any, writer io.Writer) error {
 	}
 
 	if firstElem.Kind() != reflect.Struct {
-		return fmt.Errorf("CSV output requires struct slice, got %v", firstElem.Kind())
+		return nil, fmt.Errorf("table output (csv, xlsx, markdown) requires struct slice, got %v", firstElem.Kind())
 	}
 
 	// ヘッダーを生成
@@ -77,12 +155,9 @@ func encodeCSV(data any, writer io.Writer) error {
 		headers = append(headers, field.Name)
 	}
 
-	// ヘッダーを書き込み
-	if err := csvWriter.Write(headers); err != nil {
-		return fmt.Errorf("error writing CSV headers: %v", err)
-	}
+	rows := [][]string{headers}
 
-	// データ行を書き込み
+	// データ行を生成
 	for i := 0; i < val.Len(); i++ {
 		elem := val.Index(i)
 		if elem.Kind() == reflect.Ptr {
@@ -95,12 +170,10 @@ func encodeCSV(data any, writer io.Writer) error {
 			row = append(row, formatFieldValue(field))
 		}
 
-		if err := csvWriter.Write(row); err != nil {
-			return fmt.Errorf("error writing CSV row %d: %v", i, err)
-		}
+		rows = append(rows, row)
 	}
 
-	return nil
+	return rows, nil
 }
 
 // formatFieldValue はフィールドの値を文字列に変換します
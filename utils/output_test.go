@@ -200,3 +200,28 @@ func TestPrintOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintOutputNDJSON(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var buf bytes.Buffer
+	err := PrintOutput([]item{{1, "a&b"}, {2, "c"}}, OutputFormatNDJSON, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"a&b\"}\n{\"id\":2,\"name\":\"c\"}\n", buf.String())
+
+	buf.Reset()
+	err = PrintOutput(item{3, "d"}, OutputFormatNDJSON, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":3,\"name\":\"d\"}\n", buf.String())
+}
+
+func TestTableRowsErrors(t *testing.T) {
+	_, err := tableRows("not a slice")
+	assert.ErrorContains(t, err, "table output (csv, xlsx, markdown) requires slice data")
+
+	_, err = tableRows([]int{1})
+	assert.ErrorContains(t, err, "table output (csv, xlsx, markdown) requires struct slice")
+}
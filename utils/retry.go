@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// RetryOptions は再試行の回数と待ち時間の設定です
+// ゼロ値は再試行しません
+type RetryOptions struct {
+	// MaxRetries は最初の試行に加えて再試行する回数です
+	MaxRetries int
+	// Backoff は最初の再試行までの待ち時間で、再試行ごとに倍になります
+	Backoff time.Duration
+	// MaxBackoff は待ち時間の上限です
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryOptions はAPI呼び出しの既定の再試行設定です
+var DefaultRetryOptions = RetryOptions{
+	MaxRetries: 5,
+	Backoff:    time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// statusPattern は SDK が返すエラーメッセージからステータスコードを取り出します
+var statusPattern = regexp.MustCompile(`status: (\d{3})`)
+
+// ErrorStatusCode は SDK が返したエラーから HTTP ステータスコードを取り出します
+// ステータスコードを含まないエラーでは false を返します
+func ErrorStatusCode(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+	m := statusPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code, true
+}
+
+// IsNotFoundError は SDK が 404 を返したエラーかどうかを返します
+func IsNotFoundError(err error) bool {
+	code, ok := ErrorStatusCode(err)
+	return ok && code == 404
+}
+
+// IsRetryableError はレート制限 (429)・サーバーエラー (5xx)・ネットワークエラーのように
+// 再試行すれば成功する可能性があるエラーかどうかを返します
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	code, ok := ErrorStatusCode(err)
+	return ok && (code == 429 || code >= 500)
+}
+
+// Retry は fn が再試行可能なエラーを返す間、指数バックオフで再試行します
+func Retry[T any](opts RetryOptions, fn func() (T, error)) (T, error) {
+	wait := opts.Backoff
+	for attempt := 0; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= opts.MaxRetries || !IsRetryableError(err) {
+			return result, err
+		}
+		time.Sleep(wait)
+		wait *= 2
+		if opts.MaxBackoff > 0 {
+			wait = min(wait, opts.MaxBackoff)
+		}
+	}
+}
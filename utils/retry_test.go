@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "rate limited", err: errors.New("request failed with status: 429"), expected: true},
+		{name: "server error", err: errors.New("request failed with status: 503"), expected: true},
+		{name: "wrapped", err: fmt.Errorf("listing: %w", errors.New("request failed with status: 500")), expected: true},
+		{name: "client error", err: errors.New("request failed with status: 404"), expected: false},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: true},
+		{name: "other", err: assert.AnError, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRetryableError(tt.err))
+		})
+	}
+}
+
+func TestIsNotFoundError(t *testing.T) {
+	assert.True(t, IsNotFoundError(errors.New("request failed with status: 404")))
+	assert.True(t, IsNotFoundError(fmt.Errorf("getting app: %w", errors.New("request failed with status: 404"))))
+	assert.False(t, IsNotFoundError(errors.New("request failed with status: 403")))
+	assert.False(t, IsNotFoundError(assert.AnError))
+	assert.False(t, IsNotFoundError(nil))
+}
+
+func TestRetry(t *testing.T) {
+	opts := RetryOptions{MaxRetries: 3, Backoff: time.Millisecond}
+	rateLimited := errors.New("request failed with status: 429")
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		calls := 0
+		got, err := Retry(opts, func() (int, error) {
+			calls++
+			if calls < 3 {
+				return 0, rateLimited
+			}
+			return 42, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 42, got)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		calls := 0
+		_, err := Retry(opts, func() (int, error) {
+			calls++
+			return 0, rateLimited
+		})
+		assert.Equal(t, rateLimited, err)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		calls := 0
+		_, err := Retry(opts, func() (int, error) {
+			calls++
+			return 0, assert.AnError
+		})
+		assert.Equal(t, assert.AnError, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("zero options", func(t *testing.T) {
+		calls := 0
+		_, _ = Retry(RetryOptions{}, func() (int, error) {
+			calls++
+			return 0, rateLimited
+		})
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pepabo/onecli/version"
+)
+
+const (
+	OutputFormatCEF    OutputFormat = "cef"
+	OutputFormatLEEF   OutputFormat = "leef"
+	OutputFormatSyslog OutputFormat = "syslog"
+)
+
+const (
+	siemVendor  = "OneLogin"
+	siemProduct = "onecli"
+
+	// syslogFacility は authpriv (10) です
+	syslogFacility = 10
+	// syslogSDID は RFC 5424 の構造化データID です
+	// 32473 は RFC 5612 でドキュメント用に予約されたエンタープライズ番号です
+	syslogSDID = "onelogin@32473"
+)
+
+// SIEMRecord はCEF/LEEF/syslog形式で出力する際の共通フィールドです
+type SIEMRecord struct {
+	Time      time.Time
+	ID        string
+	TypeID    int32
+	TypeName  string
+	ActorID   int32
+	ActorName string
+	UserID    int32
+	UserName  string
+	SourceIP  string
+	AppID     int32
+	AppName   string
+	RiskScore int32
+	Message   string
+}
+
+// SIEMRecorder はSIEM形式で出力できるデータが実装するインターフェースです
+type SIEMRecorder interface {
+	SIEMRecord() SIEMRecord
+}
+
+// IsLineFormat は1件ごとに1行で出力する形式かどうかを返します
+// 追記型の出力(follow や export)ではこれらの形式のみ使用できます
+func IsLineFormat(format OutputFormat) bool {
+	switch format {
+	case OutputFormatNDJSON, OutputFormatCEF, OutputFormatLEEF, OutputFormatSyslog:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeSIEM はスライスまたは単一の値をSIEM形式で1件1行としてエンコードします
+func encodeSIEM(data any, format OutputFormat, writer io.Writer) error {
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Slice {
+		return writeSIEMLine(data, format, writer)
+	}
+	for i := 0; i < val.Len(); i++ {
+		if err := writeSIEMLine(val.Index(i).Interface(), format, writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSIEMLine(item any, format OutputFormat, writer io.Writer) error {
+	recorder, ok := item.(SIEMRecorder)
+	if !ok {
+		return fmt.Errorf("%s output is only supported for events, got %T", format, item)
+	}
+	r := recorder.SIEMRecord()
+
+	var line string
+	switch format {
+	case OutputFormatCEF:
+		line = formatCEF(r)
+	case OutputFormatLEEF:
+		line = formatLEEF(r)
+	case OutputFormatSyslog:
+		line = formatSyslog(r)
+	default:
+		return fmt.Errorf("unsupported SIEM format: %s", format)
+	}
+	_, err := io.WriteString(writer, line+"\n")
+	return err
+}
+
+// siemSeverity はリスクスコア(0-100)をCEF/LEEFの重要度(0-10)に変換します
+func siemSeverity(riskScore int32) int {
+	return min(max(int(riskScore)/10, 0), 10)
+}
+
+// formatCEF は ArcSight Common Event Format で1行を生成します
+func formatCEF(r SIEMRecord) string {
+	header := []string{
+		"CEF:0",
+		cefHeaderEscape(siemVendor),
+		cefHeaderEscape(siemProduct),
+		cefHeaderEscape(version.Version),
+		strconv.Itoa(int(r.TypeID)),
+		cefHeaderEscape(r.TypeName),
+		strconv.Itoa(siemSeverity(r.RiskScore)),
+	}
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscape(value))
+		}
+	}
+	addID := func(key string, id int32) {
+		if id != 0 {
+			add(key, strconv.Itoa(int(id)))
+		}
+	}
+	if !r.Time.IsZero() {
+		add("rt", strconv.FormatInt(r.Time.UnixMilli(), 10))
+	}
+	add("externalId", r.ID)
+	add("suser", r.ActorName)
+	addID("suid", r.ActorID)
+	add("duser", r.UserName)
+	addID("duid", r.UserID)
+	add("src", r.SourceIP)
+	if r.AppName != "" {
+		add("cs1Label", "app")
+		add("cs1", r.AppName)
+	}
+	if r.AppID != 0 {
+		add("cn2Label", "appId")
+		addID("cn2", r.AppID)
+	}
+	add("cn1Label", "riskScore")
+	add("cn1", strconv.Itoa(int(r.RiskScore)))
+	add("msg", r.Message)
+
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " ")
+}
+
+func cefHeaderEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ").Replace(s)
+}
+
+func cefExtensionEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+// formatLEEF は IBM QRadar の Log Event Extended Format 2.0 でタブ区切りの1行を生成します
+func formatLEEF(r SIEMRecord) string {
+	header := []string{
+		"LEEF:2.0",
+		leefEscape(siemVendor),
+		leefEscape(siemProduct),
+		leefEscape(version.Version),
+		strconv.Itoa(int(r.TypeID)),
+		"x09",
+	}
+
+	var attrs []string
+	add := func(key, value string) {
+		if value != "" {
+			attrs = append(attrs, key+"="+leefEscape(value))
+		}
+	}
+	addID := func(key string, id int32) {
+		if id != 0 {
+			add(key, strconv.Itoa(int(id)))
+		}
+	}
+	if !r.Time.IsZero() {
+		add("devTime", strconv.FormatInt(r.Time.UnixMilli(), 10))
+		add("devTimeFormat", "epoch")
+	}
+	add("cat", r.TypeName)
+	add("sev", strconv.Itoa(siemSeverity(r.RiskScore)))
+	add("eventId", r.ID)
+	add("actorName", r.ActorName)
+	addID("actorId", r.ActorID)
+	add("usrName", r.UserName)
+	addID("usrId", r.UserID)
+	add("src", r.SourceIP)
+	add("appName", r.AppName)
+	addID("appId", r.AppID)
+	add("riskScore", strconv.Itoa(int(r.RiskScore)))
+	add("msg", r.Message)
+
+	return strings.Join(header, "|") + "|" + strings.Join(attrs, "\t")
+}
+
+func leefEscape(s string) string {
+	return strings.NewReplacer("|", " ", "\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// formatSyslog は RFC 5424 形式で1行を生成します
+// イベントの各フィールドは構造化データとして出力します
+func formatSyslog(r SIEMRecord) string {
+	// リスクスコアが高いものは warning、それ以外は informational
+	severity := 6
+	if r.RiskScore >= 50 {
+		severity = 4
+	}
+
+	timestamp := "-"
+	if !r.Time.IsZero() {
+		timestamp = r.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+
+	msgID := "-"
+	if r.TypeID != 0 {
+		msgID = strconv.Itoa(int(r.TypeID))
+	}
+
+	var params []string
+	add := func(key, value string) {
+		if value != "" {
+			params = append(params, key+`="`+syslogParamEscape(value)+`"`)
+		}
+	}
+	addID := func(key string, id int32) {
+		if id != 0 {
+			add(key, strconv.Itoa(int(id)))
+		}
+	}
+	add("id", r.ID)
+	addID("event_type_id", r.TypeID)
+	add("event_type", r.TypeName)
+	addID("actor_user_id", r.ActorID)
+	add("actor_user_name", r.ActorName)
+	addID("user_id", r.UserID)
+	add("user_name", r.UserName)
+	add("ipaddr", r.SourceIP)
+	addID("app_id", r.AppID)
+	add("app_name", r.AppName)
+	add("risk_score", strconv.Itoa(int(r.RiskScore)))
+
+	sd := "[" + syslogSDID + " " + strings.Join(params, " ") + "]"
+
+	msg := r.TypeName
+	if r.Message != "" {
+		msg += ": " + r.Message
+	}
+	msg = strings.NewReplacer("\n", " ", "\r", " ").Replace(msg)
+
+	return fmt.Sprintf("<%d>1 %s %s %s - %s %s %s",
+		syslogFacility*8+severity, timestamp, "onelogin", siemProduct, msgID, sd, msg)
+}
+
+func syslogParamEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pepabo/onecli/version"
+	"github.com/stretchr/testify/assert"
+)
+
+type siemItem struct {
+	record SIEMRecord
+}
+
+func (i siemItem) SIEMRecord() SIEMRecord {
+	return i.record
+}
+
+func TestSIEMFormats(t *testing.T) {
+	record := SIEMRecord{
+		Time:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ID:        "100",
+		TypeID:    5,
+		TypeName:  "USER_LOGGED_INTO_ONELOGIN",
+		ActorID:   1,
+		ActorName: "admin",
+		UserID:    2,
+		UserName:  "alice",
+		SourceIP:  "192.0.2.1",
+		AppID:     3,
+		AppName:   "Slack",
+		RiskScore: 72,
+		Message:   `a=b|c "d"`,
+	}
+
+	tests := []struct {
+		name     string
+		format   OutputFormat
+		expected string
+	}{
+		{
+			name:   "cef",
+			format: OutputFormatCEF,
+			expected: "CEF:0|OneLogin|onecli|" + version.Version + "|5|USER_LOGGED_INTO_ONELOGIN|7|" +
+				`rt=1704164645000 externalId=100 suser=admin suid=1 duser=alice duid=2 src=192.0.2.1 ` +
+				`cs1Label=app cs1=Slack cn2Label=appId cn2=3 cn1Label=riskScore cn1=72 msg=a\=b|c "d"` + "\n",
+		},
+		{
+			name:   "leef",
+			format: OutputFormatLEEF,
+			expected: "LEEF:2.0|OneLogin|onecli|" + version.Version + "|5|x09|" +
+				"devTime=1704164645000\tdevTimeFormat=epoch\tcat=USER_LOGGED_INTO_ONELOGIN\tsev=7\teventId=100\t" +
+				"actorName=admin\tactorId=1\tusrName=alice\tusrId=2\tsrc=192.0.2.1\tappName=Slack\tappId=3\t" +
+				"riskScore=72\tmsg=a=b c \"d\"\n",
+		},
+		{
+			name:   "syslog",
+			format: OutputFormatSyslog,
+			expected: `<84>1 2024-01-02T03:04:05.000000Z onelogin onecli - 5 [onelogin@32473 id="100" event_type_id="5" ` +
+				`event_type="USER_LOGGED_INTO_ONELOGIN" actor_user_id="1" actor_user_name="admin" user_id="2" ` +
+				`user_name="alice" ipaddr="192.0.2.1" app_id="3" app_name="Slack" risk_score="72"] ` +
+				`USER_LOGGED_INTO_ONELOGIN: a=b|c "d"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := PrintOutput([]siemItem{{record}}, tt.format, &buf)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestSIEMEscaping(t *testing.T) {
+	record := SIEMRecord{TypeName: `a|b\c`, Message: "line1\nline2", AppName: `x"]y`}
+
+	assert.Contains(t, formatCEF(record), `|a\|b\\c|`)
+	assert.Contains(t, formatCEF(record), `msg=line1\nline2`)
+	assert.Contains(t, formatSyslog(record), `app_name="x\"\]y"`)
+	assert.Contains(t, formatSyslog(record), "line1 line2")
+	assert.Contains(t, formatLEEF(record), "msg=line1 line2")
+}
+
+func TestSIEMSeverity(t *testing.T) {
+	assert.Equal(t, 0, siemSeverity(-5))
+	assert.Equal(t, 0, siemSeverity(9))
+	assert.Equal(t, 5, siemSeverity(50))
+	assert.Equal(t, 10, siemSeverity(100))
+	assert.Equal(t, 10, siemSeverity(150))
+}
+
+func TestSIEMUnsupportedType(t *testing.T) {
+	var buf bytes.Buffer
+	err := PrintOutput([]string{"a"}, OutputFormatCEF, &buf)
+	assert.Error(t, err)
+}
+
+func TestIsLineFormat(t *testing.T) {
+	assert.True(t, IsLineFormat(OutputFormatNDJSON))
+	assert.True(t, IsLineFormat(OutputFormatSyslog))
+	assert.False(t, IsLineFormat(OutputFormatJSON))
+	assert.False(t, IsLineFormat(OutputFormatCSV))
+}
+
+func TestPrintStreamSIEM(t *testing.T) {
+	items := []siemItem{{SIEMRecord{ID: "1", TypeID: 5}}, {SIEMRecord{ID: "2", TypeID: 6}}}
+
+	for _, format := range []OutputFormat{OutputFormatCEF, OutputFormatLEEF, OutputFormatSyslog} {
+		t.Run(string(format), func(t *testing.T) {
+			var want, got bytes.Buffer
+			assert.NoError(t, PrintOutput(items, format, &want))
+			assert.NoError(t, PrintStream(seqOf(items, nil), format, &got))
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
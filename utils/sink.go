@@ -0,0 +1,447 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SinkOptions はシンクのバッチ・リトライ・バッファの設定です
+type SinkOptions struct {
+	// BatchSize は1回の送信にまとめる件数です
+	BatchSize int
+	// MaxBuffer は送信できずに溜めておける最大件数です
+	// これを超えると Write はエラーを返します
+	MaxBuffer int
+	// FlushInterval はバッチが埋まらなくても送信する間隔です
+	FlushInterval time.Duration
+	// MaxRetries は送信に失敗したときの再試行回数です
+	MaxRetries int
+	// Backoff は最初の再試行までの待ち時間で、再試行ごとに倍になります
+	Backoff time.Duration
+	// MaxBackoff は再試行の待ち時間の上限です
+	MaxBackoff time.Duration
+	// Format は syslog シンクで送る行の形式です (syslog, cef, leef)
+	Format OutputFormat
+	// Timeout は接続とHTTPリクエストのタイムアウトです
+	Timeout time.Duration
+	// Secret を指定すると HTTP シンクはリクエストに HMAC-SHA256 の署名ヘッダを付けます
+	Secret string
+	// Unbatched を指定すると HTTP シンクは1件ずつ JSON オブジェクトとして POST します
+	// BatchSize は1になります
+	Unbatched bool
+	// DeadLetterDir を指定すると再試行しても送信できなかったバッチをこのディレクトリに保存し、
+	// バッファから取り除きます。保存したバッチは RedeliverDeadLetters で再送できます
+	DeadLetterDir string
+}
+
+// DefaultSinkOptions はシンクの既定の設定を返します
+func DefaultSinkOptions() SinkOptions {
+	return SinkOptions{
+		BatchSize:     100,
+		MaxBuffer:     10000,
+		FlushInterval: time.Second,
+		MaxRetries:    5,
+		Backoff:       500 * time.Millisecond,
+		MaxBackoff:    30 * time.Second,
+		Format:        OutputFormatSyslog,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// sinkSender は1バッチを送信先へ送ります
+type sinkSender interface {
+	send(batch []any) error
+	close() error
+}
+
+// permanentError は再試行しても成功しない送信エラーです
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Sink はイベントを syslog や HTTP のコレクタへ直接送信します
+// 書き込まれたデータはバッファに溜め、BatchSize 件ごとまたは FlushInterval ごとに
+// まとめて送信します。送信に失敗したデータはバッファに残り、次の送信で再試行されます
+type Sink struct {
+	sender sinkSender
+	opts   SinkOptions
+
+	mu  sync.Mutex
+	buf []any
+	// deadLetters は DeadLetterDir に保存した件数です
+	deadLetters int
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSink はURLのスキームに応じたシンクを作成します
+//   - syslog+tcp://host:port  改行区切りの syslog を TCP で送信
+//   - syslog+udp://host:port  1件1データグラムの syslog を UDP で送信
+//   - http(s)://...           JSON配列のバッチを POST で送信
+func NewSink(rawURL string, opts SinkOptions) (*Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink URL: %v", err)
+	}
+
+	defaults := DefaultSinkOptions()
+	if opts.Unbatched {
+		opts.BatchSize = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaults.BatchSize
+	}
+	if opts.MaxBuffer < opts.BatchSize {
+		opts.MaxBuffer = max(defaults.MaxBuffer, opts.BatchSize)
+	}
+	if opts.Format == "" {
+		opts.Format = defaults.Format
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaults.MaxBackoff
+	}
+
+	var sender sinkSender
+	switch u.Scheme {
+	case "syslog+tcp", "syslog+udp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid sink URL %q: missing host", rawURL)
+		}
+		switch opts.Format {
+		case OutputFormatSyslog, OutputFormatCEF, OutputFormatLEEF:
+		default:
+			return nil, fmt.Errorf("syslog sink does not support output format: %s", opts.Format)
+		}
+		sender = &syslogSender{
+			network: u.Scheme[len("syslog+"):],
+			addr:    u.Host,
+			format:  opts.Format,
+			timeout: opts.Timeout,
+		}
+	case "http", "https":
+		sender = &httpSender{
+			url:       u.String(),
+			client:    &http.Client{Timeout: opts.Timeout},
+			secret:    opts.Secret,
+			unbatched: opts.Unbatched,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sink scheme %q (use syslog+tcp, syslog+udp, http or https)", u.Scheme)
+	}
+
+	return newSink(sender, opts), nil
+}
+
+func newSink(sender sinkSender, opts SinkOptions) *Sink {
+	s := &Sink{
+		sender: sender,
+		opts:   opts,
+		done:   make(chan struct{}),
+	}
+	if opts.FlushInterval > 0 {
+		s.wg.Add(1)
+		go s.flushLoop()
+	}
+	return s
+}
+
+func (s *Sink) flushLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			// 失敗したデータはバッファに残り、次の送信で再試行されます
+			_ = s.Flush()
+		}
+	}
+}
+
+// Write はデータをバッファに追加し、バッチが埋まったら送信します
+// 一時的な送信エラーではデータをバッファに残して後で再試行しますが、
+// バッファの上限を超えた場合や再試行しても成功しないエラーの場合はエラーを返します
+func (s *Sink) Write(item any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buf) >= s.opts.MaxBuffer {
+		// 溜まったデータを送れるか先に試す
+		if err := s.flushLocked(); err != nil {
+			return fmt.Errorf("sink buffer is full (%d events): %w", len(s.buf), err)
+		}
+	}
+	s.buf = append(s.buf, item)
+	if len(s.buf) < s.opts.BatchSize {
+		return nil
+	}
+	var perm *permanentError
+	if err := s.flushLocked(); errors.As(err, &perm) {
+		return err
+	}
+	return nil
+}
+
+// Flush はバッファのデータをすべて送信します
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+// Discard はバッファに残っているデータを送信せずに捨てます
+// 呼び出し側が後でデータを送り直す場合に、Close で二重に送信されるのを防ぎます
+func (s *Sink) Discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = nil
+}
+
+// Close は残りのデータを送信して接続を閉じます
+func (s *Sink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	err := s.Flush()
+	return errors.Join(err, s.sender.close())
+}
+
+func (s *Sink) flushLocked() error {
+	for len(s.buf) > 0 {
+		n := min(len(s.buf), s.opts.BatchSize)
+		if err := s.sendWithRetry(s.buf[:n]); err != nil {
+			if s.opts.DeadLetterDir == "" {
+				return err
+			}
+			// 送れなかったバッチはディスクに退避して次のバッチに進む
+			if dlErr := s.writeDeadLetter(s.buf[:n]); dlErr != nil {
+				return errors.Join(err, dlErr)
+			}
+		}
+		s.buf = s.buf[n:]
+	}
+	return nil
+}
+
+// writeDeadLetter はバッチを JSON 配列として DeadLetterDir に保存します
+func (s *Sink) writeDeadLetter(batch []any) error {
+	b, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("error writing dead letter: %v", err)
+	}
+	if err := os.MkdirAll(s.opts.DeadLetterDir, 0o700); err != nil {
+		return fmt.Errorf("error writing dead letter: %v", err)
+	}
+	// ファイル名は時刻順に並ぶようにする
+	name := fmt.Sprintf("%d-*.json", time.Now().UnixNano())
+	f, err := os.CreateTemp(s.opts.DeadLetterDir, name)
+	if err != nil {
+		return fmt.Errorf("error writing dead letter: %v", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing dead letter: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing dead letter: %v", err)
+	}
+	s.deadLetters += len(batch)
+	return nil
+}
+
+// DeadLetters は DeadLetterDir に保存した件数を返します
+func (s *Sink) DeadLetters() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deadLetters
+}
+
+// RedeliverDeadLetters は DeadLetterDir に保存されたバッチを古い順に再送し、
+// 再送できたファイルを削除します。再送した件数を返します
+// 再送に失敗したバッチは新しいファイルとして保存し直されます
+func (s *Sink) RedeliverDeadLetters() (int, error) {
+	if s.opts.DeadLetterDir == "" {
+		return 0, nil
+	}
+	files, err := filepath.Glob(filepath.Join(s.opts.DeadLetterDir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	slices.Sort(files)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.flushLocked(); err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return delivered, err
+		}
+		var batch []json.RawMessage
+		if err := json.Unmarshal(b, &batch); err != nil {
+			return delivered, fmt.Errorf("invalid dead letter %s: %v", file, err)
+		}
+		for _, item := range batch {
+			s.buf = append(s.buf, item)
+		}
+		// 送れなかった分は flushLocked が新しいファイルに保存し直す
+		before := s.deadLetters
+		if err := s.flushLocked(); err != nil {
+			return delivered, err
+		}
+		if err := os.Remove(file); err != nil {
+			return delivered, err
+		}
+		delivered += len(batch) - (s.deadLetters - before)
+	}
+	return delivered, nil
+}
+
+// sendWithRetry は失敗した送信を指数バックオフで再試行します
+func (s *Sink) sendWithRetry(batch []any) error {
+	wait := s.opts.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = s.sender.send(batch)
+		if err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) || attempt >= s.opts.MaxRetries {
+			return fmt.Errorf("error sending %d event(s) to sink: %w", len(batch), err)
+		}
+		time.Sleep(wait)
+		wait = min(wait*2, s.opts.MaxBackoff)
+	}
+}
+
+// syslogSender は syslog 形式の行を TCP または UDP で送信します
+type syslogSender struct {
+	network string
+	addr    string
+	format  OutputFormat
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func (s *syslogSender) send(batch []any) error {
+	lines := make([][]byte, 0, len(batch))
+	for _, item := range batch {
+		var buf bytes.Buffer
+		if err := writeSIEMLine(item, s.format, &buf); err != nil {
+			return &permanentError{err}
+		}
+		lines = append(lines, buf.Bytes())
+	}
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.addr, s.timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+
+	var err error
+	if s.network == "udp" {
+		// UDP は1件ずつ別のデータグラムで送る
+		for _, line := range lines {
+			if _, err = s.conn.Write(bytes.TrimSuffix(line, []byte("\n"))); err != nil {
+				break
+			}
+		}
+	} else {
+		// TCP は改行区切り (RFC 6587 non-transparent framing) でまとめて送る
+		_, err = s.conn.Write(bytes.Join(lines, nil))
+	}
+	if err != nil {
+		// 次の再試行で接続し直す
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *syslogSender) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// httpSender はバッチをJSON配列として POST します
+// unbatched の場合は1件を JSON オブジェクトとして POST します
+type httpSender struct {
+	url       string
+	client    *http.Client
+	secret    string
+	unbatched bool
+}
+
+func (s *httpSender) send(batch []any) error {
+	var payload any = batch
+	if s.unbatched && len(batch) == 1 {
+		payload = batch[0]
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(payload); err != nil {
+		return &permanentError{err}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, &body)
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		setWebhookSignature(req.Header, s.secret, time.Now(), body.Bytes())
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("sink returned %s", resp.Status)
+	// 429 と 5xx は一時的なエラーとして再試行する
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return &permanentError{err}
+}
+
+func (s *httpSender) close() error {
+	return nil
+}
@@ -0,0 +1,307 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSinkOptions() SinkOptions {
+	return SinkOptions{
+		BatchSize:  2,
+		MaxBuffer:  4,
+		MaxRetries: 3,
+		Backoff:    time.Millisecond,
+		Format:     OutputFormatSyslog,
+	}
+}
+
+func TestSinkSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	sink, err := NewSink("syslog+tcp://"+ln.Addr().String(), testSinkOptions())
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, sink.Write(siemItem{SIEMRecord{ID: id, TypeID: 5}}))
+	}
+	require.NoError(t, sink.Close())
+
+	for _, id := range []string{"1", "2", "3"} {
+		select {
+		case line := <-lines:
+			assert.True(t, strings.HasPrefix(line, "<"))
+			assert.Contains(t, line, `id="`+id+`"`)
+		case <-time.After(time.Second):
+			t.Fatalf("event %s was not received", id)
+		}
+	}
+}
+
+func TestSinkSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	opts := testSinkOptions()
+	opts.Format = OutputFormatCEF
+	sink, err := NewSink("syslog+udp://"+conn.LocalAddr().String(), opts)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(siemItem{SIEMRecord{ID: "1"}}))
+	require.NoError(t, sink.Write(siemItem{SIEMRecord{ID: "2"}}))
+	require.NoError(t, sink.Close())
+
+	buf := make([]byte, 4096)
+	for _, id := range []string{"1", "2"} {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		datagram := string(buf[:n])
+		assert.True(t, strings.HasPrefix(datagram, "CEF:0|"))
+		assert.Contains(t, datagram, "externalId="+id)
+		assert.False(t, strings.HasSuffix(datagram, "\n"))
+	}
+}
+
+func TestSinkHTTPBatchesAndRetries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		batches  [][]map[string]any
+		requests atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request fails so the batch has to be retried
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var batch []map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, testSinkOptions())
+	require.NoError(t, err)
+
+	for i := range 3 {
+		require.NoError(t, sink.Write(map[string]any{"id": i}))
+	}
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, int32(3), requests.Load())
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+}
+
+func TestSinkHTTPPermanentError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, testSinkOptions())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write("a"))
+	assert.Error(t, sink.Write("b"))
+	// A 4xx response is not retried
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Error(t, sink.Close())
+}
+
+func TestSinkDiscard(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, testSinkOptions())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write("a"))
+	sink.Discard()
+	require.NoError(t, sink.Close())
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+func TestSinkBufferFull(t *testing.T) {
+	// Nothing listens on the port, so every send fails
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	opts := testSinkOptions()
+	opts.MaxRetries = 0
+	sink, err := NewSink("syslog+tcp://"+addr, opts)
+	require.NoError(t, err)
+
+	for i := range 4 {
+		assert.NoError(t, sink.Write(siemItem{SIEMRecord{ID: string(rune('0' + i))}}))
+	}
+	err = sink.Write(siemItem{SIEMRecord{ID: "5"}})
+	assert.ErrorContains(t, err, "sink buffer is full")
+	assert.Error(t, sink.Close())
+}
+
+func TestSinkFlushInterval(t *testing.T) {
+	received := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer server.Close()
+
+	opts := testSinkOptions()
+	opts.FlushInterval = 10 * time.Millisecond
+	sink, err := NewSink(server.URL, opts)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	// A partial batch is sent by the periodic flush
+	require.NoError(t, sink.Write("a"))
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("partial batch was not flushed")
+	}
+}
+
+func TestNewSinkInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		opts SinkOptions
+	}{
+		{name: "unknown scheme", url: "ftp://example.com"},
+		{name: "missing host", url: "syslog+tcp://"},
+		{name: "unsupported format", url: "syslog+udp://127.0.0.1:514", opts: SinkOptions{Format: OutputFormatCSV}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSink(tt.url, tt.opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSinkHTTPSignedUnbatched(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, VerifyWebhook("secret", r.Header, body, time.Now(), time.Minute))
+		var item map[string]any
+		assert.NoError(t, json.Unmarshal(body, &item))
+		mu.Lock()
+		bodies = append(bodies, item)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	opts := testSinkOptions()
+	opts.Secret = "secret"
+	opts.Unbatched = true
+	sink, err := NewSink(server.URL, opts)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(map[string]any{"id": 1}))
+	require.NoError(t, sink.Write(map[string]any{"id": 2}))
+	require.NoError(t, sink.Close())
+
+	// Each event is posted on its own as a JSON object
+	require.Len(t, bodies, 2)
+	assert.Equal(t, float64(1), bodies[0]["id"])
+	assert.Equal(t, float64(2), bodies[1]["id"])
+}
+
+func TestSinkDeadLetters(t *testing.T) {
+	var (
+		failing  atomic.Bool
+		received atomic.Int32
+	)
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var batch []any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		received.Add(int32(len(batch)))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	opts := testSinkOptions()
+	opts.MaxRetries = 1
+	opts.DeadLetterDir = dir
+	sink, err := NewSink(server.URL, opts)
+	require.NoError(t, err)
+
+	// The batches that cannot be delivered are spooled instead of failing
+	for i := range 3 {
+		require.NoError(t, sink.Write(map[string]any{"id": i}))
+	}
+	require.NoError(t, sink.Flush())
+	assert.Equal(t, 3, sink.DeadLetters())
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Redelivery fails again and spools the batches anew
+	n, err := sink.RedeliverDeadLetters()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	files, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	failing.Store(false)
+	n, err = sink.RedeliverDeadLetters()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, int32(3), received.Load())
+	files, err = filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	require.NoError(t, sink.Close())
+}
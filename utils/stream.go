@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/goccy/go-yaml"
+)
+
+// PrintStream はイテレータから届いたデータを1件ずつ指定された形式で出力します
+// 全件を溜めずに出力するため、大量のデータでもすぐに先頭から表示されます
+// 出力結果は同じデータのスライスを PrintOutput に渡した場合と同じです
+// xlsx は形式上逐次出力できないため、全件を受け取ってから出力します
+func PrintStream[T any](seq iter.Seq2[T, error], format OutputFormat, writer io.Writer) error {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	switch format {
+	case OutputFormatJSON:
+		return streamJSON(seq, writer)
+	case OutputFormatNDJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetEscapeHTML(false)
+		for item, err := range seq {
+			if err != nil {
+				return err
+			}
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputFormatCEF, OutputFormatLEEF, OutputFormatSyslog:
+		for item, err := range seq {
+			if err != nil {
+				return err
+			}
+			if err := writeSIEMLine(item, format, writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputFormatCSV:
+		return streamCSV(seq, writer)
+	case OutputFormatXLSX:
+		items := []T{}
+		for item, err := range seq {
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return PrintOutput(items, format, writer)
+	default:
+		return streamYAML(seq, writer)
+	}
+}
+
+// streamJSON は PrintOutput と同じインデント付きのJSON配列を要素ごとに書き出します
+func streamJSON[T any](seq iter.Seq2[T, error], writer io.Writer) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("  ", "  ")
+	encoder.SetEscapeHTML(false)
+
+	count := 0
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+
+		buf.Reset()
+		if count == 0 {
+			buf.WriteString("[\n  ")
+		} else {
+			buf.WriteString(",\n  ")
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		// Encode が付ける末尾の改行は区切り側で出力する
+		buf.Truncate(buf.Len() - 1)
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		count++
+	}
+
+	end := "\n]\n"
+	if count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(writer, end)
+	return err
+}
+
+// streamCSV は最初の要素からヘッダーを生成し、1行ずつCSVを書き出します
+func streamCSV[T any](seq iter.Seq2[T, error], writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	count := 0
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+
+		rows, err := tableRows([]T{item})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			if err := csvWriter.Write(rows[0]); err != nil {
+				return fmt.Errorf("error writing CSV headers: %v", err)
+			}
+		}
+		if err := csvWriter.Write(rows[1]); err != nil {
+			return fmt.Errorf("error writing CSV row %d: %v", count, err)
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return err
+		}
+		count++
+	}
+	return nil
+}
+
+// streamYAML は要素ごとに1要素のシーケンスとして書き出し、全体で1つのYAMLシーケンスにします
+func streamYAML[T any](seq iter.Seq2[T, error], writer io.Writer) error {
+	count := 0
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal([]T{item})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(b); err != nil {
+			return err
+		}
+		count++
+	}
+	if count == 0 {
+		_, err := io.WriteString(writer, "[]\n")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bytes"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/onelogin/onelogin-go-sdk/v4/pkg/onelogin/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func seqOf[T any](items []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+func TestPrintStream(t *testing.T) {
+	users := []models.User{
+		{
+			ID:        1,
+			Username:  "testuser1",
+			Email:     "test1@example.com",
+			Firstname: "Test <1>",
+			CreatedAt: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:       2,
+			Username: "testuser2",
+			Email:    "test2@example.com",
+		},
+	}
+
+	for _, format := range []OutputFormat{OutputFormatJSON, OutputFormatNDJSON, OutputFormatYAML, OutputFormatCSV} {
+		for _, data := range [][]models.User{users, {}} {
+			t.Run(string(format), func(t *testing.T) {
+				var want, got bytes.Buffer
+				assert.NoError(t, PrintOutput(data, format, &want))
+				assert.NoError(t, PrintStream(seqOf(data, nil), format, &got))
+				assert.Equal(t, want.String(), got.String())
+			})
+		}
+	}
+}
+
+func TestPrintStreamError(t *testing.T) {
+	var buf bytes.Buffer
+	err := PrintStream(seqOf([]models.User{{ID: 1}}, assert.AnError), OutputFormatNDJSON, &buf)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	// Items before the error are already written
+	assert.Contains(t, buf.String(), `"id":1`)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseTime は日時の指定を解釈して時刻を返します
+// 次の形式を受け付けます
+//   - "now", "today", "yesterday" (today と yesterday はローカルタイムの0時)
+//   - "15m", "7d", "1w" のような相対時間 (now からさかのぼった時刻)
+//   - "2024-01-02" (UTCの0時)
+//   - "2024-01-02T03:04:05+09:00" のようなRFC3339形式
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	switch strings.ToLower(s) {
+	case "":
+		return time.Time{}, fmt.Errorf("invalid time: empty string")
+	case "now":
+		return now, nil
+	case "today":
+		return startOfDay(now), nil
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := ParseDuration(strings.TrimPrefix(s, "-")); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 15m or 7d, YYYY-MM-DD, RFC3339, now, today or yesterday", s)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// ParseMonth は "2026-09" のような年月を解釈し、その月の初め(UTC)と翌月の初めを返します
+func ParseMonth(s string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q (use YYYY-MM)", s)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2026, 10, 19, 8, 30, 0, 0, jst)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{input: "now", expected: now},
+		{input: "today", expected: time.Date(2026, 10, 19, 0, 0, 0, 0, jst)},
+		{input: "Yesterday", expected: time.Date(2026, 10, 18, 0, 0, 0, 0, jst)},
+		{input: "15m", expected: now.Add(-15 * time.Minute)},
+		{input: "-2h", expected: now.Add(-2 * time.Hour)},
+		{input: "7d", expected: now.AddDate(0, 0, -7)},
+		{input: "1w2d", expected: now.AddDate(0, 0, -9)},
+		{input: "2026-10-01", expected: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "2026-10-01T09:00:00+09:00", expected: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "", wantErr: true},
+		{input: "last week", wantErr: true},
+		{input: "2026-13-01", wantErr: true},
+		{input: "2026-10-01 09:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTime(tt.input, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "expected %v, got %v", tt.expected, got)
+		})
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	start, end, err := ParseMonth("2026-09")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end, err = ParseMonth("2026-12")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+	assert.Equal(t, 12, int(start.Month()))
+
+	for _, input := range []string{"", "2026", "2026-13", "09-2026"} {
+		_, _, err := ParseMonth(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader は "sha256=" に続けて HMAC-SHA256 の16進数を入れるヘッダです
+	WebhookSignatureHeader = "X-Onecli-Signature"
+	// WebhookTimestampHeader は署名した時刻 (Unix 秒) を入れるヘッダです
+	WebhookTimestampHeader = "X-Onecli-Timestamp"
+)
+
+// SignWebhook はタイムスタンプとボディを "." でつないだ文字列の HMAC-SHA256 署名を返します
+// タイムスタンプを署名に含めることで、受信側はリプレイを拒否できます
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook は受信したリクエストの署名を検証します
+// タイムスタンプが now から tolerance 以上離れている場合もエラーを返します
+func VerifyWebhook(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s header", WebhookTimestampHeader)
+	}
+	if d := now.Sub(time.Unix(timestamp, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("timestamp is outside the tolerance of %s", tolerance)
+	}
+	signature := header.Get(WebhookSignatureHeader)
+	if !strings.HasPrefix(signature, "sha256=") {
+		return fmt.Errorf("missing or invalid %s header", WebhookSignatureHeader)
+	}
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// setWebhookSignature は署名ヘッダを設定します
+func setWebhookSignature(header http.Header, secret string, now time.Time, body []byte) {
+	timestamp := now.Unix()
+	header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, body))
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1760000000.{"id":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=a1565c8097b1493a466621b36fa98c03a4e2527a543b26cd1a2f221076f1b261",
+		SignWebhook("secret", 1760000000, []byte(`{"id":1}`)))
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte(`{"id":1}`)
+	header := func(secret string, at time.Time) http.Header {
+		h := http.Header{}
+		setWebhookSignature(h, secret, at, body)
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    []byte
+		wantErr string
+	}{
+		{name: "valid", header: header("secret", now), body: body},
+		{name: "wrong secret", header: header("other", now), body: body, wantErr: "signature mismatch"},
+		{name: "modified body", header: header("secret", now), body: []byte(`{"id":2}`), wantErr: "signature mismatch"},
+		{name: "too old", header: header("secret", now.Add(-10*time.Minute)), body: body, wantErr: "tolerance"},
+		{name: "missing timestamp", header: http.Header{}, body: body, wantErr: WebhookTimestampHeader},
+		{
+			name: "missing signature",
+			header: http.Header{
+				WebhookTimestampHeader: []string{strconv.FormatInt(now.Unix(), 10)},
+			},
+			body:    body,
+			wantErr: WebhookSignatureHeader,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhook("secret", tt.header, tt.body, now, 5*time.Minute)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
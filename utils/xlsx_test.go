@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][]string{
+		{"email", "App <1>"},
+		{"user@example.com", "x"},
+	}
+
+	require.NoError(t, encodeXLSX(rows, "Sheet1", &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	var sheet string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			_ = rc.Close()
+			sheet = string(b)
+		}
+	}
+
+	assert.Contains(t, names, "[Content_Types].xml")
+	assert.Contains(t, names, "xl/workbook.xml")
+	assert.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t xml:space="preserve">App &lt;1&gt;</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">user@example.com</t></is></c>`)
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumnName(0))
+	assert.Equal(t, "Z", xlsxColumnName(25))
+	assert.Equal(t, "AA", xlsxColumnName(26))
+	assert.Equal(t, "AZ", xlsxColumnName(51))
+	assert.Equal(t, "BA", xlsxColumnName(52))
+}
+
+func TestPrintTable(t *testing.T) {
+	var buf bytes.Buffer
+	rows := [][]string{{"a", "b"}, {"1", "2"}}
+
+	assert.NoError(t, PrintTable(rows, OutputFormatCSV, &buf))
+	assert.Equal(t, "a,b\n1,2\n", buf.String())
+
+	assert.Error(t, PrintTable(rows, OutputFormatYAML, &buf))
+}